@@ -0,0 +1,48 @@
+package analysis
+
+import (
+	"../commons"
+	"strconv"
+)
+
+var aggregateOrderArr = []string{commons.METRIC, commons.RUNS, commons.MEAN, commons.STD_DEV, commons.CI95, commons.LOWER, commons.UPPER}
+
+/*
+WriteAggregate writes the mean of the throughput & of every latency metric across the runs of the result files, with
+its 95% confidence interval (Student's t, the runs being the samples).
+*/
+func WriteAggregate(resultFileArr []string, aggregateFilePath string) {
+	metricArr := append([]string{"Throughput"}, latencyMetricArr...)
+	metricVsValuesMap := make(map[string][]float64)
+
+	for _, resultFilePath := range resultFileArr {
+		invocationArr := GetMeasured(ReadResultFile(resultFilePath))
+		stats := GetRunStats(invocationArr)
+		metricVsValuesMap["Throughput"] = append(metricVsValuesMap["Throughput"], stats.Throughput)
+
+		for idx, value := range getLatencyMetrics(getLatencies(invocationArr)) {
+			metricVsValuesMap[latencyMetricArr[idx]] = append(metricVsValuesMap[latencyMetricArr[idx]], value)
+		}
+	}
+
+	commons.OutputFileWriter = commons.CreateOutputFile(aggregateFilePath)
+	commons.PrintToStdOutOnVerbose("Aggregate of " + strconv.Itoa(len(resultFileArr)) + " runs:")
+	commons.PrintHeader(aggregateOrderArr, aggregateFilePath)
+
+	for _, metric := range metricArr {
+		valueArr := metricVsValuesMap[metric]
+		mean, ci95 := commons.Mean(valueArr), commons.ConfidenceInterval95(valueArr)
+
+		aggregateMap := make(map[string]string)
+		aggregateMap[commons.METRIC] = metric
+		aggregateMap[commons.RUNS] = strconv.Itoa(len(valueArr))
+		aggregateMap[commons.MEAN] = strconv.FormatFloat(mean, 'f', 2, 64)
+		aggregateMap[commons.STD_DEV] = strconv.FormatFloat(commons.StdDev(valueArr), 'f', 2, 64)
+		aggregateMap[commons.CI95] = strconv.FormatFloat(ci95, 'f', 2, 64)
+		aggregateMap[commons.LOWER] = strconv.FormatFloat(mean-ci95, 'f', 2, 64)
+		aggregateMap[commons.UPPER] = strconv.FormatFloat(mean+ci95, 'f', 2, 64)
+		commons.WriteMapToFile(aggregateMap, aggregateOrderArr)
+	}
+
+	commons.OutputFileWriter.Close()
+}
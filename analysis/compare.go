@@ -0,0 +1,234 @@
+package analysis
+
+import (
+	"../commons"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+)
+
+var CompareThreshold = 10.0
+var BootstrapRounds = 1000
+
+var compareOrderArr = []string{commons.TRIAL, commons.METRIC, commons.BASELINE, commons.CANDIDATE, commons.DELTA, commons.CI_LOW, commons.CI_HIGH, commons.P_VALUE, commons.VERDICT}
+
+var latencyMetricArr = []string{"MeanLatency", "P50Latency", "P90Latency", "P99Latency"}
+
+type trialPair struct {
+	Name          string
+	BaselinePath  string
+	CandidatePath string
+}
+
+/*
+Compare compares every result file (or directory of results) with the first one, the baseline. Directories are aligned
+by the file names of their trials. The delta of each metric comes with a 95% bootstrap confidence interval, latency
+deltas also with the p-value of a Mann-Whitney U test of the two distributions. A change beyond CompareThreshold percent
+whose interval excludes zero is a regression (or an improvement), true is returned when there's any regression.
+*/
+func Compare(pathArr []string, outputFilePath string) bool {
+	if len(pathArr) < 2 {
+		panic(fmt.Errorf("Nothing to compare the baseline %v with", pathArr))
+	}
+
+	if outputFilePath != "" {
+		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
+	}
+
+	commons.PrintHeader(compareOrderArr, outputFilePath)
+
+	regressionCount := 0
+	for _, candidatePath := range pathArr[1:] {
+		for _, pair := range alignTrials(pathArr[0], candidatePath) {
+			regressionCount += compareTrial(pair, outputFilePath)
+		}
+	}
+
+	if outputFilePath != "" {
+		commons.OutputFileWriter.Close()
+	}
+
+	fmt.Println(strconv.Itoa(regressionCount) + " regressions beyond " + strconv.FormatFloat(CompareThreshold, 'f', -1, 64) + "%")
+	return regressionCount > 0
+}
+
+/* pair up the trials, two files are compared as they are whatever their names */
+func alignTrials(baselinePath string, candidatePath string) []trialPair {
+	if !isDir(baselinePath) && !isDir(candidatePath) {
+		return []trialPair{{Name: getResultName(candidatePath), BaselinePath: baselinePath, CandidatePath: candidatePath}}
+	}
+
+	candidateVsPathMap := make(map[string]string)
+	for _, resultFilePath := range GetResultFiles([]string{candidatePath}) {
+		candidateVsPathMap[getResultName(resultFilePath)] = resultFilePath
+	}
+
+	baselineFileArr := GetResultFiles([]string{baselinePath})
+	sort.Slice(baselineFileArr, func(i, j int) bool {
+		return IsNaturallyLess(getResultName(baselineFileArr[i]), getResultName(baselineFileArr[j]))
+	})
+
+	var pairArr []trialPair
+	for _, resultFilePath := range baselineFileArr {
+		name := getResultName(resultFilePath)
+		if otherPath, ok := candidateVsPathMap[name]; ok {
+			pairArr = append(pairArr, trialPair{Name: name, BaselinePath: resultFilePath, CandidatePath: otherPath})
+		} else {
+			commons.PrintToStdOutOnVerbose("Skipping " + name + " - not in " + candidatePath)
+		}
+	}
+
+	return pairArr
+}
+
+func isDir(path string) bool {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	return fileInfo.IsDir()
+}
+
+/* write the metrics of the trial & return the no. of regressions among them */
+func compareTrial(pair trialPair, outputFilePath string) int {
+	baselineArr := GetMeasured(ReadResultFile(pair.BaselinePath))
+	candidateArr := GetMeasured(ReadResultFile(pair.CandidatePath))
+
+	/* the same seed for every trial so that a comparison can be repeated */
+	rnd := rand.New(rand.NewSource(1))
+
+	baselineThroughput, candidateThroughput := getCompletedPerSecond(baselineArr), getCompletedPerSecond(candidateArr)
+	ciLowArr, ciHighArr := getBootstrapCIs(baselineThroughput, candidateThroughput, func(values []float64) []float64 {
+		return []float64{commons.Mean(values)}
+	}, rnd)
+
+	regressionCount := 0
+	if writeComparison(pair.Name, "Throughput", commons.Mean(baselineThroughput), commons.Mean(candidateThroughput), ciLowArr[0], ciHighArr[0], -1, false, outputFilePath) {
+		regressionCount++
+	}
+
+	baselineLatency, candidateLatency := getLatencies(baselineArr), getLatencies(candidateArr)
+	baselineMetrics, candidateMetrics := getLatencyMetrics(baselineLatency), getLatencyMetrics(candidateLatency)
+	ciLowArr, ciHighArr = getBootstrapCIs(baselineLatency, candidateLatency, getLatencyMetrics, rnd)
+	pValue := commons.MannWhitneyU(baselineLatency, candidateLatency)
+
+	for idx, metric := range latencyMetricArr {
+		if writeComparison(pair.Name, metric, baselineMetrics[idx], candidateMetrics[idx], ciLowArr[idx], ciHighArr[idx], pValue, true, outputFilePath) {
+			regressionCount++
+		}
+	}
+
+	return regressionCount
+}
+
+func getCompletedPerSecond(invocationArr []Invocation) []float64 {
+	var completedArr []float64
+	for _, bin := range ComputeTimeline(invocationArr, commons.BIN_END) {
+		completedArr = append(completedArr, float64(bin.Completed))
+	}
+
+	return completedArr
+}
+
+func getLatencies(invocationArr []Invocation) []float64 {
+	var elapsedArr []float64
+	for _, invocation := range invocationArr {
+		if !invocation.IsError() {
+			elapsedArr = append(elapsedArr, invocation.Elapsed)
+		}
+	}
+
+	return elapsedArr
+}
+
+/* mean, p50, p90 & p99 in the order of latencyMetricArr */
+func getLatencyMetrics(values []float64) []float64 {
+	sortedValues := make([]float64, len(values))
+	copy(sortedValues, values)
+	sort.Float64s(sortedValues)
+
+	return []float64{commons.Mean(sortedValues), commons.SortedPercentile(sortedValues, 50), commons.SortedPercentile(sortedValues, 90), commons.SortedPercentile(sortedValues, 99)}
+}
+
+/* 95% confidence intervals of the relative deltas (%) of every metric, by resampling both sides BootstrapRounds times */
+func getBootstrapCIs(baselineValues []float64, candidateValues []float64, getMetrics func([]float64) []float64, rnd *rand.Rand) ([]float64, []float64) {
+	metricCount := len(getMetrics(baselineValues))
+	deltasArr := make([][]float64, metricCount)
+
+	if len(baselineValues) > 0 && len(candidateValues) > 0 {
+		for round := 0; round < BootstrapRounds; round++ {
+			baselineMetrics := getMetrics(resample(baselineValues, rnd))
+			candidateMetrics := getMetrics(resample(candidateValues, rnd))
+			for idx := range deltasArr {
+				deltasArr[idx] = append(deltasArr[idx], getDeltaPercent(baselineMetrics[idx], candidateMetrics[idx]))
+			}
+		}
+	}
+
+	ciLowArr, ciHighArr := make([]float64, metricCount), make([]float64, metricCount)
+	for idx, deltaArr := range deltasArr {
+		ciLowArr[idx] = commons.Percentile(deltaArr, 2.5)
+		ciHighArr[idx] = commons.Percentile(deltaArr, 97.5)
+	}
+
+	return ciLowArr, ciHighArr
+}
+
+func resample(values []float64, rnd *rand.Rand) []float64 {
+	sampleArr := make([]float64, len(values))
+	for idx := range sampleArr {
+		sampleArr[idx] = values[rnd.Intn(len(values))]
+	}
+
+	return sampleArr
+}
+
+func getDeltaPercent(baselineValue float64, candidateValue float64) float64 {
+	if baselineValue == 0 {
+		return 0
+	}
+
+	return (candidateValue - baselineValue) / baselineValue * 100
+}
+
+/* write a row of the comparison & return whether it's a regression, a higher value is worse for latencies */
+func writeComparison(trial string, metric string, baselineValue float64, candidateValue float64, ciLow float64, ciHigh float64, pValue float64, isHigherWorse bool, outputFilePath string) bool {
+	delta := getDeltaPercent(baselineValue, candidateValue)
+
+	isWorse, isBetter := delta > CompareThreshold && ciLow > 0, delta < -CompareThreshold && ciHigh < 0
+	if !isHigherWorse {
+		isWorse, isBetter = delta < -CompareThreshold && ciHigh < 0, delta > CompareThreshold && ciLow > 0
+	}
+
+	verdict := "ok"
+	if isWorse {
+		verdict = "regression"
+	} else if isBetter {
+		verdict = "improvement"
+	}
+
+	comparisonMap := make(map[string]string)
+	comparisonMap[commons.TRIAL] = trial
+	comparisonMap[commons.METRIC] = metric
+	comparisonMap[commons.BASELINE] = strconv.FormatFloat(baselineValue, 'f', 2, 64)
+	comparisonMap[commons.CANDIDATE] = strconv.FormatFloat(candidateValue, 'f', 2, 64)
+	comparisonMap[commons.DELTA] = strconv.FormatFloat(delta, 'f', 2, 64)
+	comparisonMap[commons.CI_LOW] = strconv.FormatFloat(ciLow, 'f', 2, 64)
+	comparisonMap[commons.CI_HIGH] = strconv.FormatFloat(ciHigh, 'f', 2, 64)
+	comparisonMap[commons.P_VALUE] = "-"
+	if pValue >= 0 {
+		comparisonMap[commons.P_VALUE] = strconv.FormatFloat(pValue, 'g', 3, 64)
+	}
+	comparisonMap[commons.VERDICT] = verdict
+
+	if outputFilePath != "" {
+		commons.WriteMapToFile(comparisonMap, compareOrderArr)
+	} else {
+		commons.WriteMapToOut(comparisonMap, compareOrderArr)
+	}
+
+	return isWorse
+}
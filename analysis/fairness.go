@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"../commons"
+	"math"
+	"sort"
+)
+
+/* a user along with its share of the invocations slower than the p99 of the whole run */
+type TenantStats struct {
+	UserStats
+	Violations      int
+	ViolationRate   float64
+	InvocationShare float64
+	ViolationShare  float64
+	Slowdown        float64
+}
+
+/*
+Fairness tells how evenly the users are served: Jain's index of the per user throughput & mean latency (1 when all
+the users get the same), the max/min ratios of the per user throughput & p99, and for every tenant its share of the
+p99 violations, the successful invocations slower than the p99 of the whole run, against its share of the invocations.
+The throughput of a user, over its own span, is taken against the load it offered (its invocations over the run), so
+a workload that asks more of some users isn't unfair when they get more, but a user served late is.
+*/
+type Fairness struct {
+	P99              float64
+	JainThroughput   float64
+	JainLatency      float64
+	MaxMinThroughput float64
+	MaxMinP99        float64
+	Tenants          []TenantStats
+}
+
+func GetFairness(invocationArr []Invocation) Fairness {
+	runStats := GetRunStats(invocationArr)
+	fairness := Fairness{P99: runStats.P99}
+
+	userVsViolationsMap := make(map[string]int)
+	totalViolations := 0
+	for _, invocation := range invocationArr {
+		if !invocation.IsError() && invocation.Elapsed > fairness.P99 {
+			userVsViolationsMap[invocation.UserID]++
+			totalViolations++
+		}
+	}
+
+	var throughputArr, latencyArr, p99Arr []float64
+	for _, userStats := range GetUserStats(invocationArr) {
+		tenant := TenantStats{UserStats: userStats, Violations: userVsViolationsMap[userStats.UserID]}
+		if successCount := userStats.Count - userStats.Errors; successCount > 0 {
+			tenant.ViolationRate = float64(tenant.Violations) / float64(successCount)
+			latencyArr = append(latencyArr, userStats.Mean)
+			p99Arr = append(p99Arr, userStats.P99)
+		}
+		if len(invocationArr) > 0 {
+			tenant.InvocationShare = float64(userStats.Count) / float64(len(invocationArr))
+		}
+		if totalViolations > 0 {
+			tenant.ViolationShare = float64(tenant.Violations) / float64(totalViolations)
+		}
+		if fairness.P99 > 0 {
+			tenant.Slowdown = userStats.P99 / fairness.P99
+		}
+
+		/* 1 when the user was served at the rate it offered its invocations over the run */
+		normalizedThroughput := 0.0
+		if runStats.Duration > 0 && userStats.Count > 0 {
+			normalizedThroughput = userStats.SpanThroughput / (float64(userStats.Count) / runStats.Duration)
+		}
+
+		throughputArr = append(throughputArr, normalizedThroughput)
+		fairness.Tenants = append(fairness.Tenants, tenant)
+	}
+
+	fairness.JainThroughput = commons.JainIndex(throughputArr)
+	fairness.JainLatency = commons.JainIndex(latencyArr)
+	fairness.MaxMinThroughput = getMaxMinRatio(throughputArr)
+	fairness.MaxMinP99 = getMaxMinRatio(p99Arr)
+	return fairness
+}
+
+/* the tenants with the highest p99 violation rate, the slowest p99 first on a tie */
+func (obj Fairness) GetWorstTenants(count int) []TenantStats {
+	tenantArr := make([]TenantStats, len(obj.Tenants))
+	copy(tenantArr, obj.Tenants)
+
+	sort.SliceStable(tenantArr, func(i, j int) bool {
+		if tenantArr[i].ViolationRate != tenantArr[j].ViolationRate {
+			return tenantArr[i].ViolationRate > tenantArr[j].ViolationRate
+		}
+		return tenantArr[i].P99 > tenantArr[j].P99
+	})
+
+	if len(tenantArr) > count {
+		tenantArr = tenantArr[:count]
+	}
+
+	return tenantArr
+}
+
+/* +Inf when a user got nothing at all */
+func getMaxMinRatio(values []float64) float64 {
+	if len(values) == 0 {
+		return 1
+	}
+
+	minValue, maxValue := math.Inf(1), 0.0
+	for _, value := range values {
+		minValue = math.Min(minValue, value)
+		maxValue = math.Max(maxValue, value)
+	}
+
+	if minValue == 0 {
+		if maxValue == 0 {
+			return 1
+		}
+		return math.Inf(1)
+	}
+
+	return maxValue / minValue
+}
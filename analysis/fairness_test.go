@@ -0,0 +1,40 @@
+package analysis
+
+import "testing"
+
+/* invocations of a user completing one per second from startSec, all successful */
+func getUserInvocations(userID string, count int, startSec int64) []Invocation {
+	var invocationArr []Invocation
+	for idx := int64(0); idx < int64(count); idx++ {
+		submittedAt := (startSec + idx) * 1e9
+		invocationArr = append(invocationArr, Invocation{UserID: userID, Status: "1", SubmittedAt: submittedAt, EndedAt: submittedAt + 1e9, Elapsed: 100})
+	}
+	return invocationArr
+}
+
+func TestGetFairnessSkewedThroughput(t *testing.T) {
+	/* both users offer 10 invocations, user_1 is served in the first 10 s & user_2 over the whole 20 s */
+	invocationArr := getUserInvocations("user_1", 10, 0)
+	for _, invocation := range getUserInvocations("user_2", 10, 0) {
+		invocation.SubmittedAt *= 2
+		invocation.EndedAt = invocation.SubmittedAt + 2e9
+		invocationArr = append(invocationArr, invocation)
+	}
+
+	fairness := GetFairness(invocationArr)
+	if fairness.JainThroughput >= 0.99 {
+		t.Errorf("Jain's index of a skewed throughput without errors is %.3f, expected below 1", fairness.JainThroughput)
+	}
+	if fairness.MaxMinThroughput < 1.5 {
+		t.Errorf("Max/min throughput of a skewed throughput without errors is %.2f, expected about 2", fairness.MaxMinThroughput)
+	}
+}
+
+func TestGetFairnessEvenThroughput(t *testing.T) {
+	invocationArr := append(getUserInvocations("user_1", 10, 0), getUserInvocations("user_2", 10, 0)...)
+
+	fairness := GetFairness(invocationArr)
+	if fairness.JainThroughput < 0.999 || fairness.MaxMinThroughput > 1.001 {
+		t.Errorf("Users served alike got Jain's index %.3f & max/min %.2f, expected 1", fairness.JainThroughput, fairness.MaxMinThroughput)
+	}
+}
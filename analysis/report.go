@@ -0,0 +1,207 @@
+package analysis
+
+import (
+	"../commons"
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const worstTenantCount = 10
+
+const reportStyle = `body{font-family:sans-serif;margin:24px;color:#222}table{border-collapse:collapse;margin:8px 0 16px}th,td{border:1px solid #ccc;padding:4px 8px;text-align:right}th:first-child,td:first-child{text-align:left}h2{margin-top:32px}`
+
+type resultSet struct {
+	Name        string
+	Invocations []Invocation
+}
+
+/* read the measured invocations of every result file, named after the file or its path when the names clash */
+func readResultSets(resultFileArr []string) []resultSet {
+	nameVsCountMap := make(map[string]int)
+	for _, resultFilePath := range resultFileArr {
+		nameVsCountMap[getResultName(resultFilePath)]++
+	}
+
+	var resultSetArr []resultSet
+	for _, resultFilePath := range resultFileArr {
+		name := getResultName(resultFilePath)
+		if nameVsCountMap[name] > 1 {
+			name = strings.TrimSuffix(resultFilePath, ".csv")
+		}
+
+		commons.PrintToStdOutOnVerbose("Reading " + resultFilePath)
+		resultSetArr = append(resultSetArr, resultSet{Name: name, Invocations: GetMeasured(ReadResultFile(resultFilePath))})
+	}
+
+	return resultSetArr
+}
+
+func getResultName(resultFilePath string) string {
+	return strings.TrimSuffix(filepath.Base(resultFilePath), ".csv")
+}
+
+/*
+Report writes a single HTML file, with the charts inlined as SVG so it opens offline, for the result files (or the
+result files in the directories) of execOWFile, execOWProfile or execDockerFile. Warm up rows are left out.
+*/
+func Report(pathArr []string, reportFilePath string) {
+	resultSetArr := readResultSets(GetResultFiles(pathArr))
+	if len(resultSetArr) == 0 {
+		panic(fmt.Errorf("No result files in %s", strings.Join(pathArr, " ")))
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>OpenWhisk Bench Report</title><style>` + reportStyle + `</style></head><body>`)
+	buffer.WriteString(`<h1>OpenWhisk Bench Report</h1><p>Generated at ` + time.Now().Format(time.RFC1123) + `</p>`)
+
+	writeSummaryTable(&buffer, resultSetArr)
+	writeFairnessTable(&buffer, resultSetArr)
+
+	buffer.WriteString(`<h2>Latency CDF</h2>`)
+	buffer.WriteString(getLineChart(getLatencyCdfs(resultSetArr), "ElapsedTime (ms)", "Fraction of invocations", 1))
+
+	var throughputArr, coldStartRatioArr []chartSeries
+	for _, resultSet := range resultSetArr {
+		throughput := chartSeries{Name: resultSet.Name}
+		coldStartRatio := chartSeries{Name: resultSet.Name}
+		for _, bin := range ComputeTimeline(resultSet.Invocations, commons.BIN_END) {
+			throughput.Points = append(throughput.Points, chartPoint{X: float64(bin.Second), Y: float64(bin.Completed)})
+
+			ratio := 0.0
+			if bin.Completed > 0 {
+				ratio = float64(bin.ColdStarts) / float64(bin.Completed)
+			}
+			coldStartRatio.Points = append(coldStartRatio.Points, chartPoint{X: float64(bin.Second), Y: ratio})
+		}
+		throughputArr = append(throughputArr, throughput)
+		coldStartRatioArr = append(coldStartRatioArr, coldStartRatio)
+	}
+
+	buffer.WriteString(`<h2>Throughput over time</h2>`)
+	buffer.WriteString(getLineChart(throughputArr, "Second", "Completed invocations/s", 0))
+
+	var labelArr []string
+	var breakdownArr [][]float64
+	for _, resultSet := range resultSetArr {
+		stats := GetRunStats(resultSet.Invocations)
+		labelArr = append(labelArr, resultSet.Name)
+		breakdownArr = append(breakdownArr, []float64{stats.Wait, stats.Init, stats.Run})
+	}
+
+	buffer.WriteString(`<h2>Wait, init &amp; run time</h2>`)
+	buffer.WriteString(getStackedBarChart(labelArr, []string{"Wait", "Init", "Run"}, breakdownArr, "Mean time (ms)"))
+
+	buffer.WriteString(`<h2>Cold-start ratio over time</h2>`)
+	buffer.WriteString(getLineChart(coldStartRatioArr, "Second", "Cold starts / completed", 1))
+
+	buffer.WriteString(`<h2>Per-user breakdown</h2><p>A p99 violation is a successful invocation slower than the p99 of the whole run.</p>`)
+	for _, resultSet := range resultSetArr {
+		writeUserBreakdown(&buffer, resultSet)
+	}
+
+	buffer.WriteString(`</body></html>`)
+
+	err := ioutil.WriteFile(reportFilePath, buffer.Bytes(), 0644)
+	if err != nil {
+		panic(fmt.Errorf("Cannot create file - %s", err))
+	}
+
+	commons.PrintToStdOutOnVerbose("Report written to " + reportFilePath)
+}
+
+func writeSummaryTable(buffer *bytes.Buffer, resultSetArr []resultSet) {
+	buffer.WriteString(`<h2>Summary</h2><table><tr><th>Result</th><th>Invocations</th><th>Errors</th><th>Cold starts</th><th>Duration (s)</th><th>Throughput (/s)</th><th>Mean (ms)</th><th>p50 (ms)</th><th>p90 (ms)</th><th>p99 (ms)</th><th>Max (ms)</th></tr>`)
+	for _, resultSet := range resultSetArr {
+		stats := GetRunStats(resultSet.Invocations)
+		writeTableRow(buffer, resultSet.Name, strconv.Itoa(stats.Count), strconv.Itoa(stats.Errors), strconv.Itoa(stats.ColdStarts), formatFloat(stats.Duration, 1), formatFloat(stats.Throughput, 2), formatFloat(stats.Mean, 0), formatFloat(stats.P50, 0), formatFloat(stats.P90, 0), formatFloat(stats.P99, 0), formatFloat(stats.Max, 0))
+	}
+	buffer.WriteString(`</table>`)
+}
+
+/* the CDF is drawn from the 0.5 percentiles, enough for a smooth curve without a point per invocation */
+func getLatencyCdfs(resultSetArr []resultSet) []chartSeries {
+	var cdfArr []chartSeries
+	for _, resultSet := range resultSetArr {
+		var elapsedArr []float64
+		for _, invocation := range resultSet.Invocations {
+			if !invocation.IsError() {
+				elapsedArr = append(elapsedArr, invocation.Elapsed)
+			}
+		}
+
+		cdf := chartSeries{Name: resultSet.Name}
+		if len(elapsedArr) > 0 {
+			for percent := 0.0; percent <= 100; percent += 0.5 {
+				cdf.Points = append(cdf.Points, chartPoint{X: commons.Percentile(elapsedArr, percent), Y: percent / 100})
+			}
+		}
+		cdfArr = append(cdfArr, cdf)
+	}
+
+	return cdfArr
+}
+
+/* Jain's index is 1 when every user gets the same throughput for its load (or latency), 1/n when one gets it all */
+func writeFairnessTable(buffer *bytes.Buffer, resultSetArr []resultSet) {
+	buffer.WriteString(`<h2>Fairness across users</h2><table><tr><th>Result</th><th>Users</th><th>Jain's index (throughput / load)</th><th>Jain's index (mean latency)</th><th>Max/min throughput / load</th><th>Max/min p99</th><th>Run p99 (ms)</th></tr>`)
+	for _, resultSet := range resultSetArr {
+		fairness := GetFairness(resultSet.Invocations)
+		writeTableRow(buffer, resultSet.Name, strconv.Itoa(len(fairness.Tenants)), formatFloat(fairness.JainThroughput, 3), formatFloat(fairness.JainLatency, 3), formatFloat(fairness.MaxMinThroughput, 2), formatFloat(fairness.MaxMinP99, 2), formatFloat(fairness.P99, 0))
+	}
+	buffer.WriteString(`</table>`)
+}
+
+func writeUserBreakdown(buffer *bytes.Buffer, resultSet resultSet) {
+	fairness := GetFairness(resultSet.Invocations)
+
+	var labelArr []string
+	var latencyArr [][]float64
+	for _, tenant := range fairness.Tenants {
+		labelArr = append(labelArr, tenant.UserID)
+		latencyArr = append(latencyArr, []float64{tenant.P50, tenant.P99 - tenant.P50})
+	}
+
+	buffer.WriteString(`<h3>` + html.EscapeString(resultSet.Name) + `</h3>`)
+	buffer.WriteString(getStackedBarChart(labelArr, []string{"p50", "p50 to p99"}, latencyArr, "ElapsedTime (ms)"))
+
+	buffer.WriteString(`<h4>Worst-served tenants</h4>`)
+	writeTenantTable(buffer, fairness.GetWorstTenants(worstTenantCount))
+
+	buffer.WriteString(`<h4>All tenants</h4>`)
+	writeTenantTable(buffer, fairness.Tenants)
+}
+
+func writeTenantTable(buffer *bytes.Buffer, tenantArr []TenantStats) {
+	buffer.WriteString(`<table><tr><th>User</th><th>Invocations</th><th>Errors</th><th>Cold starts</th><th>Throughput (/s)</th><th>p50 (ms)</th><th>p90 (ms)</th><th>p99 (ms)</th><th>p99 violations</th><th>Violation rate (%)</th><th>Share of invocations (%)</th><th>Share of violations (%)</th></tr>`)
+	for _, tenant := range tenantArr {
+		writeTableRow(buffer, tenant.UserID, strconv.Itoa(tenant.Count), strconv.Itoa(tenant.Errors), strconv.Itoa(tenant.ColdStarts), formatFloat(tenant.Throughput, 2), formatFloat(tenant.P50, 0), formatFloat(tenant.P90, 0), formatFloat(tenant.P99, 0), strconv.Itoa(tenant.Violations), formatFloat(tenant.ViolationRate*100, 1), formatFloat(tenant.InvocationShare*100, 1), formatFloat(tenant.ViolationShare*100, 1))
+	}
+	buffer.WriteString(`</table>`)
+}
+
+func writeTableRow(buffer *bytes.Buffer, cellArr ...string) {
+	buffer.WriteString(`<tr>`)
+	for _, cell := range cellArr {
+		buffer.WriteString(`<td>` + html.EscapeString(cell) + `</td>`)
+	}
+	buffer.WriteString(`</tr>`)
+}
+
+func formatFloat(value float64, precision int) string {
+	return strconv.FormatFloat(value, 'f', precision, 64)
+}
+
+/* the report goes to the output file when one is given, next to the first result file (or directory) otherwise */
+func GetReportFilePath(outputFilePath string, pathArr []string) string {
+	if outputFilePath != "" {
+		return strings.TrimSuffix(outputFilePath, ".csv") + ".html"
+	}
+
+	return strings.TrimSuffix(filepath.Clean(pathArr[0]), ".csv") + "_report.html"
+}
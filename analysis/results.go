@@ -0,0 +1,229 @@
+package analysis
+
+import (
+	"../commons"
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/* an invocation (or docker command) read back from a result file, times in ms except the unix timestamps (ns) */
+type Invocation struct {
+	Batch       int
+	UserID      string
+	FunctionID  string
+	Phase       string
+	Status      string
+	Check       string
+	SubmittedAt int64
+	EndedAt     int64
+	Elapsed     float64
+	Wait        float64
+	Init        float64
+	Run         float64
+	Row         map[string]string
+}
+
+/* docker results have no status column, their rows are only written for the commands that went through */
+func (obj Invocation) IsError() bool {
+	return (obj.Status != "" && obj.Status != "1") || obj.IsCheckFailed()
+}
+
+/* the invocation went through but its result isn't the expected one */
+func (obj Invocation) IsCheckFailed() bool {
+	return obj.Check == "failed"
+}
+
+func (obj Invocation) IsWarmup() bool {
+	return obj.Phase == "warmup"
+}
+
+func (obj Invocation) IsColdStart() bool {
+	return obj.Init > 0
+}
+
+/* build the invocation from a result row, either as written to the file or as passed to processResult */
+func NewInvocation(rowMap map[string]string) Invocation {
+	if cmdResult, ok := rowMap[commons.CMD_RESULT]; ok {
+		rowMap = commons.CopyMap(rowMap)
+		resultParts := strings.Split(cmdResult, ", ")
+		for idx, key := range []string{commons.ACTIVATION_ID, commons.WAIT_TIME, commons.INIT_TIME, commons.RUN_TIME} {
+			if idx < len(resultParts) {
+				rowMap[key] = resultParts[idx]
+			}
+		}
+	}
+
+	parseFloat := func(key string) float64 {
+		value, _ := strconv.ParseFloat(rowMap[key], 64)
+		return value
+	}
+
+	parseInt := func(key string) int64 {
+		value, _ := strconv.ParseInt(rowMap[key], 10, 64)
+		return value
+	}
+
+	return Invocation{
+		Batch:       int(parseInt(commons.BATCH)),
+		UserID:      rowMap[commons.USER_ID],
+		FunctionID:  rowMap[commons.FUNCTION_ID],
+		Phase:       rowMap[commons.PHASE],
+		Status:      rowMap[commons.CMD_STATUS],
+		Check:       rowMap[commons.CHECK_STATUS],
+		SubmittedAt: parseInt(commons.SUBMITTED_AT),
+		EndedAt:     parseInt(commons.ENDED_AT),
+		Elapsed:     parseFloat(commons.ELAPSED_TIME),
+		Wait:        parseFloat(commons.WAIT_TIME),
+		Init:        parseFloat(commons.INIT_TIME),
+		Run:         parseFloat(commons.RUN_TIME),
+		Row:         rowMap,
+	}
+}
+
+/* read the rows of a result file written by execOWFile, execOWProfile or execDockerFile */
+func ReadResultFile(resultFilePath string) []Invocation {
+	fread, err := os.Open(resultFilePath)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+	defer fread.Close()
+
+	scanner := bufio.NewScanner(fread)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var headerArr []string
+	var invocationArr []Invocation
+	lineNo, skippedCount, repairedCount := 0, 0, 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		lineParts := strings.Split(line, ",")
+		if headerArr == nil {
+			for _, header := range lineParts {
+				headerArr = append(headerArr, strings.TrimSpace(header))
+			}
+			continue
+		}
+
+		var rowMap map[string]string
+		if len(lineParts) == len(headerArr) {
+			rowMap = make(map[string]string)
+			for idx, value := range lineParts {
+				rowMap[headerArr[idx]] = strings.TrimSpace(value)
+			}
+		} else if rowMap = getErrorRow(headerArr, lineParts); rowMap != nil {
+			repairedCount++
+		} else {
+			commons.PrintToStdOutOnDebug("Skipping " + resultFilePath + ":" + strconv.Itoa(lineNo) + " - " + strconv.Itoa(len(lineParts)) + " columns instead of " + strconv.Itoa(len(headerArr)))
+			skippedCount++
+			continue
+		}
+
+		invocationArr = append(invocationArr, NewInvocation(rowMap))
+	}
+
+	if skippedCount > 0 || repairedCount > 0 {
+		commons.PrintToStdOutOnVerbose(resultFilePath + ": " + strconv.Itoa(repairedCount) + " rows without the activation result read as errors, " + strconv.Itoa(skippedCount) + " unreadable rows skipped")
+	}
+
+	if err := scanner.Err(); err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	if headerArr == nil {
+		panic(fmt.Errorf("Empty result file - %s", resultFilePath))
+	}
+
+	return invocationArr
+}
+
+/*
+a failed invocation writes the raw output of wsk (with or without commas) instead of the 4 columns of the activation
+result: the columns before & after them are still in place, the output is kept as the activation id of an error row
+*/
+func getErrorRow(headerArr []string, lineParts []string) map[string]string {
+	resultIdx := -1
+	for idx := 0; idx+3 < len(headerArr); idx++ {
+		if headerArr[idx] == commons.ACTIVATION_ID && headerArr[idx+3] == commons.RUN_TIME {
+			resultIdx = idx
+			break
+		}
+	}
+
+	tailCount := len(headerArr) - resultIdx - 4
+	if resultIdx < 0 || len(lineParts) <= resultIdx+tailCount {
+		return nil
+	}
+
+	rowMap := make(map[string]string)
+	for idx := 0; idx < resultIdx; idx++ {
+		rowMap[headerArr[idx]] = strings.TrimSpace(lineParts[idx])
+	}
+	for idx := 0; idx < tailCount; idx++ {
+		rowMap[headerArr[len(headerArr)-1-idx]] = strings.TrimSpace(lineParts[len(lineParts)-1-idx])
+	}
+
+	rowMap[commons.ACTIVATION_ID] = strings.TrimSpace(strings.Join(lineParts[resultIdx:len(lineParts)-tailCount], ","))
+	if _, ok := rowMap[commons.CMD_STATUS]; ok {
+		rowMap[commons.CMD_STATUS] = "0"
+	}
+
+	return rowMap
+}
+
+/* the invocations of the measured workload, without the warm up */
+func GetMeasured(invocationArr []Invocation) []Invocation {
+	var measuredArr []Invocation
+	for _, invocation := range invocationArr {
+		if !invocation.IsWarmup() {
+			measuredArr = append(measuredArr, invocation)
+		}
+	}
+
+	return measuredArr
+}
+
+/* expand the directories to the result files in them, the timelines & curves written next to results are left out */
+func GetResultFiles(pathArr []string) []string {
+	var resultFileArr []string
+	for _, path := range pathArr {
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			panic(fmt.Errorf("File error - %s", err))
+		}
+
+		if !fileInfo.IsDir() {
+			resultFileArr = append(resultFileArr, path)
+			continue
+		}
+
+		matchArr, _ := filepath.Glob(filepath.Join(path, "*.csv"))
+		sort.Strings(matchArr)
+		for _, match := range matchArr {
+			if !isDerivedFile(match) {
+				resultFileArr = append(resultFileArr, match)
+			}
+		}
+	}
+
+	return resultFileArr
+}
+
+func isDerivedFile(filePath string) bool {
+	for _, suffix := range []string{"_timeline.csv", "_curve.csv", "_capacity.csv", "_scaling.csv", "_aggregate.csv", "_payload.csv", "_dag.csv"} {
+		if strings.HasSuffix(filePath, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package analysis
+
+import (
+	"../commons"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var numberChunkRegex = regexp.MustCompile(`\d+|\D+`)
+
+/* latency (ms) & throughput (invocations/s) of a set of invocations, the latencies are of the successful ones */
+type RunStats struct {
+	Count      int
+	Errors     int
+	ColdStarts int
+	Duration   float64
+	Throughput float64
+	Mean       float64
+	P50        float64
+	P90        float64
+	P99        float64
+	Max        float64
+	Wait       float64
+	Init       float64
+	Run        float64
+}
+
+/* SpanThroughput is over the user's own span, from its first submission to its last completion */
+type UserStats struct {
+	UserID         string
+	SpanThroughput float64
+	RunStats
+}
+
+/* the duration is from the first submission to the last completion of the invocations */
+func GetRunStats(invocationArr []Invocation) RunStats {
+	var stats RunStats
+	var elapsedArr, waitArr, initArr, runArr []float64
+	var startNs, endNs int64 = math.MaxInt64, 0
+
+	for _, invocation := range invocationArr {
+		stats.Count++
+		if invocation.SubmittedAt < startNs {
+			startNs = invocation.SubmittedAt
+		}
+		if invocation.EndedAt > endNs {
+			endNs = invocation.EndedAt
+		}
+
+		if invocation.IsError() {
+			stats.Errors++
+			continue
+		}
+
+		if invocation.IsColdStart() {
+			stats.ColdStarts++
+		}
+
+		elapsedArr = append(elapsedArr, invocation.Elapsed)
+		waitArr = append(waitArr, math.Max(0, invocation.Wait))
+		initArr = append(initArr, math.Max(0, invocation.Init))
+		runArr = append(runArr, math.Max(0, invocation.Run))
+	}
+
+	if endNs > startNs {
+		stats.Duration = float64(endNs-startNs) / 1e9
+		stats.Throughput = float64(len(elapsedArr)) / stats.Duration
+	}
+
+	stats.Mean = commons.Mean(elapsedArr)
+	stats.P50 = commons.Percentile(elapsedArr, 50)
+	stats.P90 = commons.Percentile(elapsedArr, 90)
+	stats.P99 = commons.Percentile(elapsedArr, 99)
+	stats.Max = commons.Percentile(elapsedArr, 100)
+	stats.Wait = commons.Mean(waitArr)
+	stats.Init = commons.Mean(initArr)
+	stats.Run = commons.Mean(runArr)
+	return stats
+}
+
+/* stats of every user, the throughput of a user is over the duration of the whole run so the users add up */
+func GetUserStats(invocationArr []Invocation) []UserStats {
+	runStats := GetRunStats(invocationArr)
+
+	userVsInvocationsMap := make(map[string][]Invocation)
+	for _, invocation := range invocationArr {
+		userVsInvocationsMap[invocation.UserID] = append(userVsInvocationsMap[invocation.UserID], invocation)
+	}
+
+	var userStatsArr []UserStats
+	for userID, userInvocationArr := range userVsInvocationsMap {
+		userStats := UserStats{UserID: userID, RunStats: GetRunStats(userInvocationArr)}
+		userStats.SpanThroughput = userStats.Throughput
+		if runStats.Duration > 0 {
+			userStats.Throughput = float64(userStats.Count-userStats.Errors) / runStats.Duration
+		}
+		userStatsArr = append(userStatsArr, userStats)
+	}
+
+	sort.Slice(userStatsArr, func(i, j int) bool {
+		return IsNaturallyLess(userStatsArr[i].UserID, userStatsArr[j].UserID)
+	})
+
+	return userStatsArr
+}
+
+/* user_2 comes before user_10 & 8192_2u before 8192_16u, the numbers in the names are compared by their value */
+func IsNaturallyLess(left string, right string) bool {
+	leftChunkArr := numberChunkRegex.FindAllString(left, -1)
+	rightChunkArr := numberChunkRegex.FindAllString(right, -1)
+
+	for idx := 0; idx < len(leftChunkArr) && idx < len(rightChunkArr); idx++ {
+		if leftChunkArr[idx] == rightChunkArr[idx] {
+			continue
+		}
+
+		leftNo, leftErr := strconv.Atoi(leftChunkArr[idx])
+		rightNo, rightErr := strconv.Atoi(rightChunkArr[idx])
+		if leftErr == nil && rightErr == nil {
+			return leftNo < rightNo
+		}
+
+		return leftChunkArr[idx] < rightChunkArr[idx]
+	}
+
+	return len(leftChunkArr) < len(rightChunkArr)
+}
@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+)
+
+const chartWidth = 760
+const chartHeight = 320
+const chartMarginLeft = 64
+const chartMarginRight = 160
+const chartMarginTop = 16
+const chartMarginBottom = 44
+
+var chartColors = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"}
+
+type chartPoint struct {
+	X float64
+	Y float64
+}
+
+type chartSeries struct {
+	Name   string
+	Points []chartPoint
+}
+
+/* round the maximum of an axis up to 1, 2 or 5 times a power of ten so the ticks land on readable values */
+func getAxisMax(maxValue float64) float64 {
+	if maxValue <= 0 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(maxValue)))
+	for _, factor := range []float64{1, 2, 5, 10} {
+		if maxValue <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
+
+func formatTick(value float64) string {
+	if value == math.Trunc(value) {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+
+	return strconv.FormatFloat(value, 'g', 3, 64)
+}
+
+/* axes, grid, ticks & labels of a chart, returns the functions mapping values to pixels */
+func writeChartAxes(buffer *bytes.Buffer, xMax float64, yMax float64, xLabel string, yLabel string, hasXTicks bool) (func(float64) float64, func(float64) float64) {
+	plotWidth := float64(chartWidth - chartMarginLeft - chartMarginRight)
+	plotHeight := float64(chartHeight - chartMarginTop - chartMarginBottom)
+
+	toX := func(value float64) float64 {
+		return chartMarginLeft + value/xMax*plotWidth
+	}
+	toY := func(value float64) float64 {
+		return chartMarginTop + plotHeight - value/yMax*plotHeight
+	}
+
+	for tick := 0; tick <= 5; tick++ {
+		xValue, yValue := xMax*float64(tick)/5, yMax*float64(tick)/5
+		fmt.Fprintf(buffer, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#eee"/>`, toX(0), toY(yValue), toX(xMax), toY(yValue))
+		fmt.Fprintf(buffer, `<text x="%.1f" y="%.1f" text-anchor="end" font-size="11">%s</text>`, toX(0)-6, toY(yValue)+4, formatTick(yValue))
+		if hasXTicks {
+			fmt.Fprintf(buffer, `<text x="%.1f" y="%.1f" text-anchor="middle" font-size="11">%s</text>`, toX(xValue), toY(0)+16, formatTick(xValue))
+		}
+	}
+
+	fmt.Fprintf(buffer, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333"/>`, toX(0), toY(0), toX(xMax), toY(0))
+	fmt.Fprintf(buffer, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333"/>`, toX(0), toY(0), toX(0), toY(yMax))
+	fmt.Fprintf(buffer, `<text x="%.1f" y="%d" text-anchor="middle" font-size="12">%s</text>`, toX(xMax/2), chartHeight-6, html.EscapeString(xLabel))
+	fmt.Fprintf(buffer, `<text x="14" y="%.1f" text-anchor="middle" font-size="12" transform="rotate(-90 14 %.1f)">%s</text>`, toY(yMax/2), toY(yMax/2), html.EscapeString(yLabel))
+
+	return toX, toY
+}
+
+func writeChartLegend(buffer *bytes.Buffer, nameArr []string) {
+	for idx, name := range nameArr {
+		legendY := chartMarginTop + 8 + idx*18
+		fmt.Fprintf(buffer, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/>`, chartWidth-chartMarginRight+12, legendY-10, chartColors[idx%len(chartColors)])
+		fmt.Fprintf(buffer, `<text x="%d" y="%d" font-size="11">%s</text>`, chartWidth-chartMarginRight+30, legendY, html.EscapeString(name))
+	}
+}
+
+/* line chart of the series, every series is drawn in its own color */
+func getLineChart(seriesArr []chartSeries, xLabel string, yLabel string, yMax float64) string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif">`, chartWidth, chartHeight)
+
+	xMax, maxY := 0.0, 0.0
+	for _, series := range seriesArr {
+		for _, point := range series.Points {
+			xMax = math.Max(xMax, point.X)
+			maxY = math.Max(maxY, point.Y)
+		}
+	}
+	if yMax <= 0 {
+		yMax = getAxisMax(maxY)
+	}
+
+	toX, toY := writeChartAxes(&buffer, getAxisMax(xMax), yMax, xLabel, yLabel, true)
+
+	var nameArr []string
+	for idx, series := range seriesArr {
+		nameArr = append(nameArr, series.Name)
+
+		var pointsBuffer bytes.Buffer
+		for _, point := range series.Points {
+			fmt.Fprintf(&pointsBuffer, "%.1f,%.1f ", toX(point.X), toY(point.Y))
+		}
+		fmt.Fprintf(&buffer, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`, chartColors[idx%len(chartColors)], pointsBuffer.String())
+	}
+
+	writeChartLegend(&buffer, nameArr)
+	buffer.WriteString("</svg>")
+	return buffer.String()
+}
+
+/* vertical bars of every label stacked in the order of stackNameArr, valuesArr[label][stack] */
+func getStackedBarChart(labelArr []string, stackNameArr []string, valuesArr [][]float64, yLabel string) string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif">`, chartWidth, chartHeight)
+
+	maxY := 0.0
+	for _, values := range valuesArr {
+		total := 0.0
+		for _, value := range values {
+			total += value
+		}
+		maxY = math.Max(maxY, total)
+	}
+	yMax := getAxisMax(maxY)
+
+	plotWidth := float64(chartWidth - chartMarginLeft - chartMarginRight)
+	_, toY := writeChartAxes(&buffer, math.Max(1, float64(len(labelArr))), yMax, "", yLabel, false)
+
+	barSlot := plotWidth / math.Max(1, float64(len(labelArr)))
+
+	for labelIdx, label := range labelArr {
+		barX := chartMarginLeft + float64(labelIdx)*barSlot + barSlot*0.15
+		stackTop := 0.0
+		for stackIdx, value := range valuesArr[labelIdx] {
+			fmt.Fprintf(&buffer, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s %s: %s</title></rect>`, barX, toY(stackTop+value), barSlot*0.7, toY(stackTop)-toY(stackTop+value), chartColors[stackIdx%len(chartColors)], html.EscapeString(label), html.EscapeString(stackNameArr[stackIdx]), formatTick(value))
+			stackTop += value
+		}
+
+		if len(labelArr) <= 40 {
+			fmt.Fprintf(&buffer, `<text x="%.1f" y="%.1f" text-anchor="middle" font-size="10">%s</text>`, barX+barSlot*0.35, toY(0)+14, html.EscapeString(label))
+		}
+	}
+
+	writeChartLegend(&buffer, stackNameArr)
+	buffer.WriteString("</svg>")
+	return buffer.String()
+}
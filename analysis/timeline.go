@@ -0,0 +1,159 @@
+package analysis
+
+import (
+	"../commons"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var timelineOrderArr = []string{commons.SECOND, commons.COMPLETED, commons.ERRORS, commons.IN_FLIGHT, commons.COLD_STARTS, commons.P50_LATENCY, commons.P99_LATENCY, commons.P50_WAIT, commons.P99_WAIT}
+
+/* one second of the run, latencies are of the invocations that completed without an error */
+type TimelineBin struct {
+	Second     int
+	Completed  int
+	Errors     int
+	InFlight   int
+	ColdStarts int
+	P50Elapsed float64
+	P99Elapsed float64
+	P50Wait    float64
+	P99Wait    float64
+}
+
+func (obj TimelineBin) toMap() map[string]string {
+	formatMs := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+
+	binMap := make(map[string]string)
+	binMap[commons.SECOND] = strconv.Itoa(obj.Second)
+	binMap[commons.COMPLETED] = strconv.Itoa(obj.Completed)
+	binMap[commons.ERRORS] = strconv.Itoa(obj.Errors)
+	binMap[commons.IN_FLIGHT] = strconv.Itoa(obj.InFlight)
+	binMap[commons.COLD_STARTS] = strconv.Itoa(obj.ColdStarts)
+	binMap[commons.P50_LATENCY] = formatMs(obj.P50Elapsed)
+	binMap[commons.P99_LATENCY] = formatMs(obj.P99Elapsed)
+	binMap[commons.P50_WAIT] = formatMs(obj.P50Wait)
+	binMap[commons.P99_WAIT] = formatMs(obj.P99Wait)
+	return binMap
+}
+
+func (obj TimelineBin) String() string {
+	return "Second " + strconv.Itoa(obj.Second) + ": completed " + strconv.Itoa(obj.Completed) + ", errors " + strconv.Itoa(obj.Errors) + ", in flight " + strconv.Itoa(obj.InFlight) + ", p50/p99 latency " + strconv.FormatFloat(obj.P50Elapsed, 'f', 0, 64) + "/" + strconv.FormatFloat(obj.P99Elapsed, 'f', 0, 64) + " ms, p50/p99 wait " + strconv.FormatFloat(obj.P50Wait, 'f', 0, 64) + "/" + strconv.FormatFloat(obj.P99Wait, 'f', 0, 64) + " ms"
+}
+
+/* summarize the invocations of a single bin, the in flight count is left to the caller */
+func NewTimelineBin(second int, invocationArr []Invocation) TimelineBin {
+	bin := TimelineBin{Second: second}
+	var elapsedArr, waitArr []float64
+	for _, invocation := range invocationArr {
+		if invocation.IsError() {
+			bin.Errors++
+			continue
+		}
+
+		bin.Completed++
+		if invocation.IsColdStart() {
+			bin.ColdStarts++
+		}
+		elapsedArr = append(elapsedArr, invocation.Elapsed)
+		waitArr = append(waitArr, invocation.Wait)
+	}
+
+	bin.P50Elapsed = commons.Percentile(elapsedArr, 50)
+	bin.P99Elapsed = commons.Percentile(elapsedArr, 99)
+	bin.P50Wait = commons.Percentile(waitArr, 50)
+	bin.P99Wait = commons.Percentile(waitArr, 99)
+	return bin
+}
+
+/*
+ComputeTimeline bins the measured invocations per second since the first submission, by the second they were submitted
+("submission") or completed ("completion") in. The in flight count of a bin is the no. of invocations submitted but not
+completed at its end.
+*/
+func ComputeTimeline(invocationArr []Invocation, binBy string) []TimelineBin {
+	if binBy != commons.BIN_SUBMIT && binBy != commons.BIN_END {
+		panic(fmt.Errorf("Invalid timeline binning - %s", binBy))
+	}
+
+	invocationArr = GetMeasured(invocationArr)
+	if len(invocationArr) == 0 {
+		return nil
+	}
+
+	var startNs, endNs int64 = invocationArr[0].SubmittedAt, 0
+	for _, invocation := range invocationArr {
+		if invocation.SubmittedAt < startNs {
+			startNs = invocation.SubmittedAt
+		}
+		if invocation.EndedAt > endNs {
+			endNs = invocation.EndedAt
+		}
+	}
+
+	binCount := int((endNs-startNs)/1e9) + 1
+	binVsInvocationsArr := make([][]Invocation, binCount)
+	inFlightArr := make([]int, binCount)
+	for _, invocation := range invocationArr {
+		binNs := invocation.EndedAt
+		if binBy == commons.BIN_SUBMIT {
+			binNs = invocation.SubmittedAt
+		}
+
+		if binIdx := int((binNs - startNs) / 1e9); binIdx >= 0 && binIdx < binCount {
+			binVsInvocationsArr[binIdx] = append(binVsInvocationsArr[binIdx], invocation)
+		}
+
+		/* in flight from the bin it's submitted in until the one before it's completed in */
+		firstIdx := int((invocation.SubmittedAt - startNs) / 1e9)
+		lastIdx := int((invocation.EndedAt-startNs)/1e9) - 1
+		for binIdx := firstIdx; binIdx <= lastIdx && binIdx < binCount; binIdx++ {
+			inFlightArr[binIdx]++
+		}
+	}
+
+	binArr := make([]TimelineBin, binCount)
+	for binIdx := range binArr {
+		binArr[binIdx] = NewTimelineBin(binIdx, binVsInvocationsArr[binIdx])
+		binArr[binIdx].InFlight = inFlightArr[binIdx]
+	}
+
+	return binArr
+}
+
+/* write the timeline as csv next to the result file, or to the standard output when there's no file */
+func WriteTimeline(binArr []TimelineBin, timelineFilePath string) {
+	if timelineFilePath != "" {
+		commons.OutputFileWriter = commons.CreateOutputFile(timelineFilePath)
+	}
+
+	commons.PrintHeader(timelineOrderArr, timelineFilePath)
+	for _, bin := range binArr {
+		if timelineFilePath != "" {
+			commons.WriteMapToFile(bin.toMap(), timelineOrderArr)
+		} else {
+			commons.WriteMapToOut(bin.toMap(), timelineOrderArr)
+		}
+	}
+
+	if timelineFilePath != "" {
+		commons.OutputFileWriter.Close()
+	}
+}
+
+func GetTimelineFilePath(outputFilePath string) string {
+	if outputFilePath == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(outputFilePath, ".csv") + "_timeline.csv"
+}
+
+/* Timeline computes the latency-over-time report of an existing result file */
+func Timeline(resultFilePath string, outputFilePath string, binBy string) {
+	commons.PrintToStdOutOnVerbose("Binning " + resultFilePath + " by " + binBy + " second")
+	WriteTimeline(ComputeTimeline(ReadResultFile(resultFilePath), binBy), outputFilePath)
+}
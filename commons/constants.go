@@ -4,6 +4,7 @@ const (
 	// Common Constants
 	BATCH                    = "Batch"
 	PARAMETER                = "Parameter"
+	PHASE                    = "Phase"
 	SEQ                      = "Seq"
 	ELAPSED_TIME             = "ElapsedTime"
 	ELAPSED_TIME_SINCE_START = "ElapsedTimeSinceStart"
@@ -17,11 +18,91 @@ const (
 	OPEN_WHISK_CONCURRENCY_FACTOR = 24
 
 	// Open Whisk Contants
-	USER_ID     = "UserID"
-	USER_AUTH   = "UserAuth"
-	FUNCTION_ID = "FunctionID"
-	CMD_RESULT  = "ActivationId, WaitTime, InitTime, RunTime"
-	CMD_STATUS  = "CmdStatus"
+	USER_ID      = "UserID"
+	USER_AUTH    = "UserAuth"
+	FUNCTION_ID  = "FunctionID"
+	CMD_RESULT   = "ActivationId, WaitTime, InitTime, RunTime"
+	CMD_STATUS   = "CmdStatus"
+	CHECK_STATUS = "CheckStatus"
+	THINK_TIME   = "ThinkTime"
+
+	// Columns of CMD_RESULT once written out
+	ACTIVATION_ID = "ActivationId"
+	WAIT_TIME     = "WaitTime"
+	INIT_TIME     = "InitTime"
+	RUN_TIME      = "RunTime"
+
+	// Keep-alive probing Constants
+	IDLE_GAP             = "IdleGap"
+	PROBE_ROUND          = "ProbeRound"
+	IS_COLD_START        = "ColdStart"
+	PROBES               = "Probes"
+	COLD_STARTS          = "ColdStarts"
+	EVICTION_PROBABILITY = "EvictionProbability"
+
+	// Capacity curve Constants
+	STEP        = "Step"
+	LOAD_LEVEL  = "LoadLevel"
+	COMPLETED   = "Completed"
+	ERRORS      = "Errors"
+	THROUGHPUT  = "Throughput"
+	P50_LATENCY = "P50Latency"
+	P99_LATENCY = "P99Latency"
+	IS_KNEE     = "Knee"
+
+	// Timeline Constants
+	SECOND     = "Second"
+	IN_FLIGHT  = "InFlight"
+	P50_WAIT   = "P50WaitTime"
+	P99_WAIT   = "P99WaitTime"
+	BIN_SUBMIT = "submission"
+	BIN_END    = "completion"
+
+	// Comparison Constants
+	TRIAL     = "Trial"
+	METRIC    = "Metric"
+	BASELINE  = "Baseline"
+	CANDIDATE = "Candidate"
+	DELTA     = "DeltaPercent"
+	CI_LOW    = "CILowPercent"
+	CI_HIGH   = "CIHighPercent"
+	P_VALUE   = "PValue"
+	VERDICT   = "Verdict"
+
+	// Aggregate Constants
+	MEAN    = "Mean"
+	STD_DEV = "StdDev"
+	CI95    = "CI95"
+	LOWER   = "Lower95"
+	UPPER   = "Upper95"
+
+	// Suite Constants
+	USERS       = "Users"
+	RUNS        = "Runs"
+	INVOCATIONS = "Invocations"
+
+	// Payload sweep Constants
+	PAYLOAD_SIZE = "PayloadSize"
+	MEAN_LATENCY = "MeanLatency"
+
+	// DAG Constants
+	DAG_INSTANCE = "DagInstance"
+	STEP_COPY    = "Copy"
+	STEPS        = "Steps"
+
+	// Trigger Constants
+	TRIGGER_ACTIVATION_ID = "TriggerActivationId"
+	FIRE_TIME             = "FireTime"
+	FIRE_TO_ACTIVATION    = "FireToActivation"
+	DELIVERY_DELAY        = "DeliveryDelay"
+	DELIVERED             = "Delivered"
+
+	// HTTP timing Constants
+	DNS_TIME     = "DnsTime"
+	CONNECT_TIME = "ConnectTime"
+	TLS_TIME     = "TlsTime"
+	TTFB_TIME    = "TimeToFirstByte"
+	TOTAL_TIME   = "TotalTime"
 
 	// Docker Contants
 	CONTAINER_NAME = "ContainerName"
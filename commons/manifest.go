@@ -0,0 +1,178 @@
+package commons
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MANIFEST_SUFFIX = "_manifest.json"
+
+type HostInfo struct {
+	Hostname string `json:"hostname"`
+	Kernel   string `json:"kernel"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	CPUs     int    `json:"cpus"`
+	MemoryKB int64  `json:"memoryKB"`
+}
+
+type BackendInfo struct {
+	Name    string                 `json:"name"`
+	ApiHost string                 `json:"apiHost,omitempty"`
+	Build   map[string]interface{} `json:"build,omitempty"`
+	Version string                 `json:"version,omitempty"`
+}
+
+/* Manifest records how a result file was produced, it's written next to it as <output>_manifest.json */
+type Manifest struct {
+	Command      string            `json:"command"`
+	Args         []string          `json:"args"`
+	Flags        map[string]string `json:"flags"`
+	Workload     string            `json:"workload,omitempty"`
+	WorkloadHash string            `json:"workloadSha256,omitempty"`
+	OutputFile   string            `json:"outputFile"`
+	Backend      BackendInfo       `json:"backend"`
+	Host         HostInfo          `json:"host"`
+	ToolRevision string            `json:"toolRevision"`
+	GoVersion    string            `json:"goVersion"`
+	StartedAt    time.Time         `json:"startedAt"`
+	EndedAt      time.Time         `json:"endedAt"`
+}
+
+var RunManifest Manifest
+
+/* set by the backend of the command, it's only asked once the first manifest is written */
+var DescribeBackend = func() BackendInfo {
+	return BackendInfo{}
+}
+
+var backendOnce sync.Once
+
+/* InitManifest captures what's common to every run of the process, the command with its args & flags and the host */
+func InitManifest(argsArr []string, flagMap map[string]string) {
+	RunManifest = Manifest{
+		Command:      argsArr[0],
+		Args:         argsArr[1:],
+		Flags:        flagMap,
+		Host:         getHostInfo(),
+		ToolRevision: getToolRevision(),
+		GoVersion:    runtime.Version(),
+		StartedAt:    time.Now(),
+	}
+}
+
+/* WriteManifest writes the manifest of a run that read workloadFilePath & wrote outputFilePath, no-op without output */
+func WriteManifest(outputFilePath string, workloadFilePath string, startedAt time.Time) {
+	if outputFilePath == "" {
+		return
+	}
+
+	backendOnce.Do(func() {
+		RunManifest.Backend = DescribeBackend()
+	})
+
+	manifest := RunManifest
+	manifest.Workload = workloadFilePath
+	manifest.WorkloadHash = getFileHash(workloadFilePath)
+	manifest.OutputFile = outputFilePath
+	manifest.StartedAt = startedAt
+	manifest.EndedAt = time.Now()
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	manifestFilePath := GetManifestFilePath(outputFilePath)
+	err = ioutil.WriteFile(manifestFilePath, manifestBytes, 0644)
+	if err != nil {
+		panic(fmt.Errorf("Cannot create file - %s", err))
+	}
+
+	PrintToStdOutOnVerbose("Manifest written to " + manifestFilePath)
+}
+
+func GetManifestFilePath(outputFilePath string) string {
+	return strings.TrimSuffix(outputFilePath, ".csv") + MANIFEST_SUFFIX
+}
+
+func ReadManifest(manifestFilePath string) Manifest {
+	manifestBytes, err := ioutil.ReadFile(manifestFilePath)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	var manifest Manifest
+	err = json.Unmarshal(manifestBytes, &manifest)
+	if err != nil {
+		panic(fmt.Errorf("Corrupt manifest %s - %s", manifestFilePath, err))
+	}
+
+	return manifest
+}
+
+func getFileHash(filePath string) string {
+	fread, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer fread.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, fread); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func getHostInfo() HostInfo {
+	hostInfo := HostInfo{OS: runtime.GOOS, Arch: runtime.GOARCH, CPUs: runtime.NumCPU()}
+	hostInfo.Hostname, _ = os.Hostname()
+
+	if kernelBytes, err := ioutil.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		hostInfo.Kernel = strings.TrimSpace(string(kernelBytes))
+	}
+
+	if fread, err := os.Open("/proc/meminfo"); err == nil {
+		defer fread.Close()
+
+		scanner := bufio.NewScanner(fread)
+		for scanner.Scan() {
+			lineParts := strings.Fields(scanner.Text())
+			if len(lineParts) >= 2 && lineParts[0] == "MemTotal:" {
+				hostInfo.MemoryKB, _ = strconv.ParseInt(lineParts[1], 10, 64)
+				break
+			}
+		}
+	}
+
+	return hostInfo
+}
+
+/* git revision of the benchmark, marked dirty when it runs with local changes */
+func getToolRevision() string {
+	revOut, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+
+	revision := strings.TrimSpace(string(revOut))
+	if statusOut, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output(); err == nil && len(strings.TrimSpace(string(statusOut))) > 0 {
+		revision += "-dirty"
+	}
+
+	return revision
+}
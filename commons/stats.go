@@ -0,0 +1,150 @@
+package commons
+
+import (
+	"math"
+	"sort"
+)
+
+/* percentile (0-100) of the values using linear interpolation between the closest ranks */
+func Percentile(values []float64, percent float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sortedValues := make([]float64, len(values))
+	copy(sortedValues, values)
+	sort.Float64s(sortedValues)
+
+	return SortedPercentile(sortedValues, percent)
+}
+
+/* same as Percentile for values already sorted, saves the copy & sort when many percentiles of them are needed */
+func SortedPercentile(sortedValues []float64, percent float64) float64 {
+	if len(sortedValues) == 0 {
+		return 0
+	}
+
+	rank := percent / 100 * float64(len(sortedValues)-1)
+	lowerIdx := int(math.Floor(rank))
+	upperIdx := int(math.Ceil(rank))
+	if lowerIdx == upperIdx {
+		return sortedValues[lowerIdx]
+	}
+
+	return sortedValues[lowerIdx] + (rank-float64(lowerIdx))*(sortedValues[upperIdx]-sortedValues[lowerIdx])
+}
+
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
+
+/* two-sided p-value of the Mann-Whitney U test by its normal approximation, corrected for ties */
+func MannWhitneyU(leftValues []float64, rightValues []float64) float64 {
+	leftCount, rightCount := float64(len(leftValues)), float64(len(rightValues))
+	if leftCount == 0 || rightCount == 0 {
+		return 1
+	}
+
+	type rankedValue struct {
+		Value  float64
+		IsLeft bool
+	}
+
+	rankedArr := make([]rankedValue, 0, len(leftValues)+len(rightValues))
+	for _, value := range leftValues {
+		rankedArr = append(rankedArr, rankedValue{Value: value, IsLeft: true})
+	}
+	for _, value := range rightValues {
+		rankedArr = append(rankedArr, rankedValue{Value: value})
+	}
+	sort.Slice(rankedArr, func(i, j int) bool {
+		return rankedArr[i].Value < rankedArr[j].Value
+	})
+
+	leftRankSum, tieSum := 0.0, 0.0
+	for start := 0; start < len(rankedArr); {
+		end := start
+		for end < len(rankedArr) && rankedArr[end].Value == rankedArr[start].Value {
+			end++
+		}
+
+		/* tied values share the average of their ranks */
+		avgRank := float64(start+end+1) / 2
+		for idx := start; idx < end; idx++ {
+			if rankedArr[idx].IsLeft {
+				leftRankSum += avgRank
+			}
+		}
+
+		tieCount := float64(end - start)
+		tieSum += tieCount*tieCount*tieCount - tieCount
+		start = end
+	}
+
+	totalCount := leftCount + rightCount
+	uValue := leftRankSum - leftCount*(leftCount+1)/2
+	mean := leftCount * rightCount / 2
+	variance := leftCount * rightCount / 12 * ((totalCount + 1) - tieSum/(totalCount*(totalCount-1)))
+	if variance <= 0 {
+		return 1
+	}
+
+	zScore := (uValue - mean) / math.Sqrt(variance)
+	return math.Erfc(math.Abs(zScore) / math.Sqrt2)
+}
+
+/* sample standard deviation */
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean := Mean(values)
+	sumSq := 0.0
+	for _, value := range values {
+		sumSq += (value - mean) * (value - mean)
+	}
+
+	return math.Sqrt(sumSq / float64(len(values)-1))
+}
+
+/* two-sided 97.5% quantiles of Student's t distribution by degrees of freedom, beyond 30 the normal one is close enough */
+var tQuantiles = []float64{12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228, 2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086, 2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042}
+
+/* half width of the 95% confidence interval of the mean of the values */
+func ConfidenceInterval95(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	tQuantile := 1.96
+	if len(values)-1 <= len(tQuantiles) {
+		tQuantile = tQuantiles[len(values)-2]
+	}
+
+	return tQuantile * StdDev(values) / math.Sqrt(float64(len(values)))
+}
+
+/* Jain's fairness index, 1 when all the values are equal down to 1/n when a single one gets everything */
+func JainIndex(values []float64) float64 {
+	sum, sumOfSquares := 0.0, 0.0
+	for _, value := range values {
+		sum += value
+		sumOfSquares += value * value
+	}
+
+	if sumOfSquares == 0 {
+		return 1
+	}
+
+	return sum * sum / (float64(len(values)) * sumOfSquares)
+}
@@ -62,10 +62,6 @@ func WriteMapToOut(writeMap map[string]string, printOrder []string) string {
 	var buffer bytes.Buffer
 
 	for _, key := range printOrder {
-		if ConcurrencyFactor != 1 && (key == RECEIVED_BYTES || key == TRANSMITTED_BYTES) {
-			continue
-		}
-
 		if buffer.Len() > 0 {
 			buffer.WriteString(", ")
 		}
@@ -82,10 +78,6 @@ func PrintHeader(printOrder []string, outputFilePath string) {
 	var buffer bytes.Buffer
 
 	for i := 0; i < len(printOrder); i++ {
-		if ConcurrencyFactor != 1 && (printOrder[i] == RECEIVED_BYTES || printOrder[i] == TRANSMITTED_BYTES) {
-			continue
-		}
-
 		delimiter := ", "
 		if i == len(printOrder)-1 {
 			delimiter = "\n"
@@ -144,16 +136,12 @@ func shouldPanic(output string) bool {
 }
 
 func ParseJsonResponse(jsonStr string) (string, string) {
-	jsonStr = newLineRegex.ReplaceAllString(jsonStr, " ")
-	var jsonResp map[string]interface{}
-	err := json.Unmarshal([]byte(jsonStr), &jsonResp)
+	status, output, err := ParseJsonOutput(jsonStr)
 	if err != nil {
 		fmt.Println("-----\n" + jsonStr + "\n-----")
 		panic(err)
 	}
 
-	status := jsonResp["status"].(string)
-	output := jsonResp["output"].(string)
 	if status == "0" && shouldPanic(output) {
 		panic(fmt.Errorf("Bash error - %s", output))
 	}
@@ -165,6 +153,20 @@ func ParseJsonResponse(jsonStr string) (string, string) {
 	return status, output
 }
 
+/* parse the status & output of an ow-bench.sh response, leaving the failed commands to the caller */
+func ParseJsonOutput(jsonStr string) (string, string, error) {
+	jsonStr = newLineRegex.ReplaceAllString(jsonStr, " ")
+	var jsonResp map[string]interface{}
+	err := json.Unmarshal([]byte(jsonStr), &jsonResp)
+	if err != nil {
+		return "", "", err
+	}
+
+	status, _ := jsonResp["status"].(string)
+	output, _ := jsonResp["output"].(string)
+	return status, output, nil
+}
+
 func GetNetworkUsage() []int64 {
 	cmdOut, err := exec.Command("ifconfig", "eno1").Output()
 	if err != nil {
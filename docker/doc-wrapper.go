@@ -29,6 +29,22 @@ var errInGoRoutine interface{}
 
 var orderArr = []string{commons.BATCH, commons.SEQ, commons.CONTAINER_NAME, commons.DOCKER_CMD, commons.ELAPSED_TIME, commons.ELAPSED_TIME_SINCE_START, commons.SUBMITTED_AT, commons.ENDED_AT, commons.EXEC_RATE, commons.RECEIVED_BYTES, commons.TRANSMITTED_BYTES, commons.CONCURRENCY_FACTOR, commons.PARAMETER}
 
+/* the network counters of the containers are only read with a single co-routine */
+func getOrderArr() []string {
+	if commons.ConcurrencyFactor == 1 {
+		return orderArr
+	}
+
+	var filteredOrderArr []string
+	for _, key := range orderArr {
+		if key != commons.RECEIVED_BYTES && key != commons.TRANSMITTED_BYTES {
+			filteredOrderArr = append(filteredOrderArr, key)
+		}
+	}
+
+	return filteredOrderArr
+}
+
 func ExecCmdsFromFile(inputFilePath string, outputFilePath string) {
 	defer cleanUpDocker()
 	commons.PrintToStdOutOnVerbose("Parsing File: " + inputFilePath)
@@ -61,7 +77,7 @@ func ExecCmdsFromFile(inputFilePath string, outputFilePath string) {
 		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
 	}
 
-	commons.PrintHeader(orderArr, outputFilePath)
+	commons.PrintHeader(getOrderArr(), outputFilePath)
 
 	batchArr := make([]int, 0, len(batchVsDockerFuncMap))
 	for batchOfExecution := range batchVsDockerFuncMap {
@@ -129,6 +145,7 @@ func ExecCmdsFromFile(inputFilePath string, outputFilePath string) {
 	commons.PrintToStdOutOnVerbose("Execution Rate: " + strconv.FormatFloat(float64(totalExecCount)/(elapsedTimeInMs/1000), 'f', 2, 64))
 
 	_ = commons.OutputFileWriter.Close()
+	commons.WriteManifest(outputFilePath, inputFilePath, startRun)
 }
 
 func TestCreationForever(outputFilePath string, imageID string) {
@@ -144,7 +161,7 @@ func TestCreationForever(outputFilePath string, imageID string) {
 		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
 	}
 
-	commons.PrintHeader(orderArr, outputFilePath)
+	commons.PrintHeader(getOrderArr(), outputFilePath)
 
 	go invokeCommand()
 
@@ -247,9 +264,9 @@ func processResult(resultMap map[string]string) {
 	counterMtx.Unlock()
 
 	if commons.WriteToFile {
-		commons.WriteMapToFile(resultMap, orderArr)
+		commons.WriteMapToFile(resultMap, getOrderArr())
 	} else {
-		commons.WriteMapToOut(resultMap, orderArr)
+		commons.WriteMapToOut(resultMap, getOrderArr())
 	}
 }
 
@@ -324,3 +341,15 @@ func invokeCommand() {
 		wgTime.Done()
 	}
 }
+
+/* the backend of the run manifest, with the version of the docker daemon */
+func DescribeBackend() commons.BackendInfo {
+	backendInfo := commons.BackendInfo{Name: "docker"}
+
+	versionOut, err := exec.Command("docker", "version", "--format", "{{.Server.Version}}").Output()
+	if err == nil {
+		backendInfo.Version = strings.TrimSpace(string(versionOut))
+	}
+
+	return backendInfo
+}
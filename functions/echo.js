@@ -0,0 +1 @@
+function main(args) { if (args.resultSize !== undefined) { return { payload: 'x'.repeat(args.resultSize) }; } if (args.echo === false) { return { bytes: JSON.stringify(args).length }; } return args; };
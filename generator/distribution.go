@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+/*
+Distribution of a count, given as "fixed:N", "uniform:MIN-MAX" or "zipf:S:MAX" (1 to MAX, most often 1, with the
+exponent S > 1).
+*/
+type Distribution struct {
+	Kind     string
+	Min      int
+	Max      int
+	Exponent float64
+}
+
+func (obj Distribution) String() string {
+	switch obj.Kind {
+	case "fixed":
+		return "fixed:" + strconv.Itoa(obj.Min)
+	case "zipf":
+		return "zipf:" + strconv.FormatFloat(obj.Exponent, 'f', -1, 64) + ":" + strconv.Itoa(obj.Max)
+	default:
+		return "uniform:" + strconv.Itoa(obj.Min) + "-" + strconv.Itoa(obj.Max)
+	}
+}
+
+func ParseDistribution(spec string) Distribution {
+	specParts := strings.Split(spec, ":")
+
+	var dist Distribution
+	var err error
+	switch {
+	case specParts[0] == "fixed" && len(specParts) == 2:
+		dist.Min, err = strconv.Atoi(specParts[1])
+		dist.Max = dist.Min
+	case specParts[0] == "uniform" && len(specParts) == 2:
+		rangeParts := strings.Split(specParts[1], "-")
+		if len(rangeParts) != 2 {
+			err = fmt.Errorf("range is not MIN-MAX")
+			break
+		}
+		dist.Min, err = strconv.Atoi(rangeParts[0])
+		if err == nil {
+			dist.Max, err = strconv.Atoi(rangeParts[1])
+		}
+	case specParts[0] == "zipf" && len(specParts) == 3:
+		dist.Min = 1
+		dist.Exponent, err = strconv.ParseFloat(specParts[1], 64)
+		if err == nil {
+			dist.Max, err = strconv.Atoi(specParts[2])
+		}
+		if err == nil && dist.Exponent <= 1 {
+			err = fmt.Errorf("the exponent needs to be more than 1")
+		}
+	default:
+		err = fmt.Errorf("unknown distribution")
+	}
+
+	if err == nil && (dist.Min < 0 || dist.Max < dist.Min) {
+		err = fmt.Errorf("invalid range")
+	}
+
+	if err != nil {
+		panic(fmt.Errorf("Invalid distribution %s - %s", spec, err))
+	}
+
+	dist.Kind = specParts[0]
+	return dist
+}
+
+/* returns a function drawing counts from the distribution with rnd */
+func (obj Distribution) getSampler(rnd *rand.Rand) func() int {
+	switch obj.Kind {
+	case "fixed":
+		return func() int {
+			return obj.Min
+		}
+	case "zipf":
+		zipf := rand.NewZipf(rnd, obj.Exponent, 1, uint64(obj.Max-1))
+		return func() int {
+			return int(zipf.Uint64()) + 1
+		}
+	default:
+		return func() int {
+			return obj.Min + rnd.Intn(obj.Max-obj.Min+1)
+		}
+	}
+}
+
+/* weight of every user in the invocations, "uniform" or "zipf:S" by the rank of the user, scaled to a mean of 1 */
+func getUserWeights(spec string, userCount int) []float64 {
+	weightArr := make([]float64, userCount)
+	exponent := 0.0
+
+	if spec != "uniform" {
+		specParts := strings.Split(spec, ":")
+		var err error
+		if len(specParts) == 2 && specParts[0] == "zipf" {
+			exponent, err = strconv.ParseFloat(specParts[1], 64)
+		} else {
+			err = fmt.Errorf("unknown user distribution")
+		}
+		if err != nil || exponent < 0 {
+			panic(fmt.Errorf("Invalid user distribution %s - %v", spec, err))
+		}
+	}
+
+	totalWeight := 0.0
+	for idx := range weightArr {
+		weightArr[idx] = 1 / math.Pow(float64(idx+1), exponent)
+		totalWeight += weightArr[idx]
+	}
+
+	for idx := range weightArr {
+		weightArr[idx] *= float64(userCount) / totalWeight
+	}
+
+	return weightArr
+}
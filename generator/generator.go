@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"../commons"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var Seed int64
+var Users = 10
+var UserDist = "uniform"
+var FuncsPerUser = "uniform:10-20"
+var InvocationsPerFunc = "uniform:5-20"
+var Batches = 15
+var BatchMode = "random"
+var ParamTemplate = ""
+var SplitRows = false
+var SweepPow2 = -1
+var SweepTotal = 8192
+
+var templateRegex = regexp.MustCompile(`\{([^}]*)\}`)
+
+type workloadRow struct {
+	Batch    int
+	UserID   int
+	Function int
+	Param    string
+	Count    int
+}
+
+func (obj workloadRow) String() string {
+	line := strconv.Itoa(obj.Batch) + "," + strconv.Itoa(obj.UserID) + "," + strconv.Itoa(obj.Function) + ","
+	if obj.Param != "" {
+		line += obj.Param + ","
+	}
+
+	return line + strconv.Itoa(obj.Count)
+}
+
+/*
+Generate writes a workload file for execOWFile, or with SweepPow2 >= 0 the power of two user sweep used in trials/:
+<SweepTotal>_<N>u.csv for N = 1, 2, 4 .. 2^SweepPow2 users into the output directory. The same Seed always generates
+the same workload, a Seed of 0 picks one (and prints it).
+
+Every one of the Users has FuncsPerUser functions (ids 0 to count-1) invoked InvocationsPerFunc times, scaled by the
+weight UserDist gives the user (an invocation at least). The rows are put in one of the Batches at random or round
+robin (BatchMode), and carry the ParamTemplate with its {a|b|c} (pick one), {MIN-MAX} (random int), {user}, {func} &
+{batch} parts filled in. A JSON object template ({"n": "{{randInt 1 9}}"}) is written untouched, its functions are
+filled in by the run. With SplitRows every invocation gets its own row, so the template is filled in for each.
+*/
+func Generate(outputPath string) {
+	if Seed == 0 {
+		Seed = time.Now().UnixNano()
+	}
+	commons.PrintToStdOutOnVerbose("Generating with seed " + strconv.FormatInt(Seed, 10))
+	rnd := rand.New(rand.NewSource(Seed))
+
+	if SweepPow2 >= 0 {
+		generateSweep(outputPath, rnd)
+		return
+	}
+
+	if Users <= 0 || Batches <= 0 {
+		panic(fmt.Errorf("Invalid workload - %d users in %d batches", Users, Batches))
+	}
+
+	if BatchMode != "random" && BatchMode != "roundrobin" {
+		panic(fmt.Errorf("Invalid batch mode - %s", BatchMode))
+	}
+
+	funcSampler := ParseDistribution(FuncsPerUser).getSampler(rnd)
+	invocationSampler := ParseDistribution(InvocationsPerFunc).getSampler(rnd)
+	userWeightArr := getUserWeights(UserDist, Users)
+
+	var rowArr []workloadRow
+	for userID := 0; userID < Users; userID++ {
+		funcCount := funcSampler()
+		for function := 0; function < funcCount; function++ {
+			invocationCount := invocationSampler()
+			if invocationCount <= 0 {
+				continue
+			}
+
+			/* the light users of a skewed distribution still get an invocation, so all the Users are in the workload */
+			count := int(math.Round(float64(invocationCount) * userWeightArr[userID]))
+			if count < 1 {
+				count = 1
+			}
+
+			if SplitRows {
+				for i := 0; i < count; i++ {
+					rowArr = append(rowArr, workloadRow{UserID: userID, Function: function, Count: 1})
+				}
+			} else {
+				rowArr = append(rowArr, workloadRow{UserID: userID, Function: function, Count: count})
+			}
+		}
+	}
+
+	for idx := range rowArr {
+		if BatchMode == "roundrobin" {
+			rowArr[idx].Batch = idx % Batches
+		} else {
+			rowArr[idx].Batch = rnd.Intn(Batches)
+		}
+		rowArr[idx].Param = fillTemplate(ParamTemplate, rowArr[idx], rnd)
+	}
+
+	sort.SliceStable(rowArr, func(i, j int) bool {
+		return rowArr[i].Batch < rowArr[j].Batch
+	})
+
+	writeWorkload(outputPath, rowArr)
+}
+
+/* same layout as gen_trial.sh, SweepTotal invocations split equally across the users, a row each, shuffled */
+func generateSweep(outputDirPath string, rnd *rand.Rand) {
+	if err := os.MkdirAll(outputDirPath, 0755); err != nil {
+		panic(fmt.Errorf("Cannot create directory - %s", err))
+	}
+
+	for pow := 0; pow <= SweepPow2; pow++ {
+		userCount := 1 << uint(pow)
+		perUserCount := SweepTotal / userCount
+		commons.PrintToStdOutOnVerbose("Generating " + strconv.Itoa(SweepTotal) + "_" + strconv.Itoa(userCount) + "u.csv for " + strconv.Itoa(userCount) + " users with " + strconv.Itoa(perUserCount) + " invocations")
+
+		rowArr := make([]workloadRow, 0, perUserCount*userCount)
+		for i := 0; i < perUserCount; i++ {
+			for userID := 0; userID < userCount; userID++ {
+				row := workloadRow{UserID: userID, Count: 1}
+				row.Param = fillTemplate(ParamTemplate, row, rnd)
+				rowArr = append(rowArr, row)
+			}
+		}
+
+		rnd.Shuffle(len(rowArr), func(i, j int) {
+			rowArr[i], rowArr[j] = rowArr[j], rowArr[i]
+		})
+
+		writeWorkload(filepath.Join(outputDirPath, strconv.Itoa(SweepTotal)+"_"+strconv.Itoa(userCount)+"u.csv"), rowArr)
+	}
+}
+
+/*
+a JSON object template is written as is, its {{…}} functions are filled in for every invocation when the workload runs.
+Any other filled in template can't have a comma, it's a column of the workload file
+*/
+func fillTemplate(template string, row workloadRow, rnd *rand.Rand) string {
+	var jsonTemplate map[string]interface{}
+	if json.Unmarshal([]byte(template), &jsonTemplate) == nil {
+		return template
+	}
+
+	if strings.Contains(template, ",") {
+		panic(fmt.Errorf("Invalid parameter template, it can't have a comma - %s", template))
+	}
+
+	return templateRegex.ReplaceAllStringFunc(template, func(match string) string {
+		part := match[1 : len(match)-1]
+
+		switch part {
+		case "user":
+			return strconv.Itoa(row.UserID)
+		case "func":
+			return strconv.Itoa(row.Function)
+		case "batch":
+			return strconv.Itoa(row.Batch)
+		}
+
+		if choiceArr := strings.Split(part, "|"); len(choiceArr) > 1 {
+			return choiceArr[rnd.Intn(len(choiceArr))]
+		}
+
+		if rangeParts := strings.Split(part, "-"); len(rangeParts) == 2 {
+			minValue, minErr := strconv.Atoi(rangeParts[0])
+			maxValue, maxErr := strconv.Atoi(rangeParts[1])
+			if minErr == nil && maxErr == nil && maxValue >= minValue {
+				return strconv.Itoa(minValue + rnd.Intn(maxValue-minValue+1))
+			}
+		}
+
+		panic(fmt.Errorf("Invalid parameter template part - %s", match))
+	})
+}
+
+func writeWorkload(outputFilePath string, rowArr []workloadRow) {
+	fwrite, err := os.Create(outputFilePath)
+	if err != nil {
+		panic(fmt.Errorf("Cannot create file - %s", err))
+	}
+	defer fwrite.Close()
+
+	writer := bufio.NewWriter(fwrite)
+	invocationCount := 0
+	for _, row := range rowArr {
+		writer.WriteString(row.String() + "\n")
+		invocationCount += row.Count
+	}
+
+	if err := writer.Flush(); err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	commons.PrintToStdOutOnVerbose(strconv.Itoa(len(rowArr)) + " rows, " + strconv.Itoa(invocationCount) + " invocations written to " + outputFilePath)
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"./analysis"
 	"./commons"
 	"./docker"
+	"./generator"
 	"./openwhisk"
 	"flag"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 func main() {
@@ -25,11 +28,68 @@ func main() {
 	flag.Float64Var(&commons.RateLimit, "rateLimit", 0, "Rate Limiter to maintain the execution rate")
 	isCreateFlag := flag.Bool("create", false, "Create functions before execution")
 	flag.BoolVar(&openwhisk.IsAsync, "async", false, "Invoke functions asynchronously")
+	flag.BoolVar(&openwhisk.StreamWorkload, "stream", false, "Dispatch a workload ordered by batch while it is read (\"-\" reads it from stdin, gzip compressed workloads are accepted)")
+	flag.BoolVar(&openwhisk.SessionMode, "sessions", false, "Give every user a closed loop session invoking its functions one after the other, whatever -cf is")
+	flag.StringVar(&openwhisk.ThinkTime, "thinkTime", openwhisk.ThinkTime, "Think time (ms) between two invocations of a session: fixed:MS, uniform:MIN-MAX or exp:MEAN")
+	flag.IntVar(&openwhisk.WarmupCount, "warmup", 0, "Invoke every (user, function) pair N times before the run, excluded from the summary")
+	flag.IntVar(&openwhisk.WarmupDuration, "warmupDuration", 0, "Invoke all the (user, function) pairs round robin for N seconds before the run, excluded from the summary")
+	flag.BoolVar(&openwhisk.LiveTimeline, "timeline", false, "Print per second throughput & latency while running and write the timeline next to the output")
+	flag.StringVar(&openwhisk.TimelineBinBy, "timelineBy", openwhisk.TimelineBinBy, "Bin the timeline by the submission or completion second of the invocations")
+	flag.IntVar(&openwhisk.Repetitions, "repeat", openwhisk.Repetitions, "No. of runs of the workload (or of every trial of a suite), aggregated with 95% confidence intervals")
+	flag.IntVar(&openwhisk.CoolDown, "coolDown", openwhisk.CoolDown, "Seconds to wait between two runs")
+	flag.StringVar(&openwhisk.ResetPolicy, "reset", openwhisk.ResetPolicy, "Tear down & create again the functions (actions) or functions & users (namespaces) between two runs")
+	flag.StringVar(&openwhisk.ExperimentDir, "experiments", openwhisk.ExperimentDir, "Directory the suite command stores its experiments in")
+	flag.StringVar(&openwhisk.PayloadSizes, "sizes", openwhisk.PayloadSizes, "Payload sizes (in bytes, KB or MB) swept by sweepPayloads")
+	flag.StringVar(&openwhisk.PayloadMode, "payloadMode", openwhisk.PayloadMode, "Payload of sweepPayloads: sent & returned (echo), sent only (arg) or returned only (result)")
+	flag.IntVar(&openwhisk.PayloadRounds, "payloadRounds", openwhisk.PayloadRounds, "No. of invocations per payload size")
+	flag.StringVar(&openwhisk.PayloadUser, "payloadUser", openwhisk.PayloadUser, "User whose function 0 is the echo action of sweepPayloads")
+	flag.Float64Var(&openwhisk.FireRate, "fireRate", openwhisk.FireRate, "Trigger fires per second in a batch of fireTriggers, 0 fires as fast as the co-routines go")
+	flag.IntVar(&openwhisk.DeliveryTimeout, "deliveryTimeout", openwhisk.DeliveryTimeout, "Seconds after the last fire before the fires without an activation are counted as lost")
+	flag.StringVar(&openwhisk.InvokeMode, "invokeMode", openwhisk.InvokeMode, "Invoke functions through ow-bench.sh (cli), the authenticated REST API (native) or as web actions over plain HTTP (web)")
+	flag.StringVar(&openwhisk.WebMethod, "webMethod", openwhisk.WebMethod, "HTTP method of the web action calls: GET (params in the query) or POST (params as a JSON body)")
+	flag.StringVar(&openwhisk.ApiHost, "apihost", openwhisk.ApiHost, "OpenWhisk API host used by the native client (defaults to $WSK_HOST)")
+	flag.StringVar(&openwhisk.TeardownPolicy, "teardown", openwhisk.TeardownPolicy, "Remove the functions (actions) or functions & users (namespaces) after the run; the teardown command defaults to namespaces")
+	flag.IntVar(&openwhisk.TeardownRetries, "retries", openwhisk.TeardownRetries, "No. of retries of every deletion during teardown")
+	flag.StringVar(&openwhisk.AuthCacheFile, "authCache", openwhisk.AuthCacheFile, "File caching the auth of every user per API host (empty to always ask wskadmin)")
+	flag.IntVar(&openwhisk.AuthCacheTTL, "authCacheTTL", openwhisk.AuthCacheTTL, "Hours a cached auth is trusted before it's validated against the API again")
+	flag.StringVar(&openwhisk.ActionSpecFile, "actions", "", "YAML file with the code, kind, memory, timeout & concurrency of each function (used with -create)")
+	flag.StringVar(&openwhisk.ResultCheckFile, "checks", "", "YAML file with the checks of the results of each function, the failed ones are recorded in the CheckStatus column")
+	flag.IntVar(&openwhisk.MinIdleGap, "minGap", openwhisk.MinIdleGap, "Shortest idle gap (in seconds) to probe for keep-alive eviction")
+	flag.IntVar(&openwhisk.MaxIdleGap, "maxGap", openwhisk.MaxIdleGap, "Longest idle gap (in seconds) to probe for keep-alive eviction")
+	flag.IntVar(&openwhisk.IdleGapStep, "gapStep", openwhisk.IdleGapStep, "Step (in seconds) between probed idle gaps, also the bisect resolution")
+	flag.StringVar(&openwhisk.ProbeMode, "probeMode", openwhisk.ProbeMode, "Keep-alive probing mode: sweep or bisect")
+	flag.IntVar(&openwhisk.ProbeRounds, "probeRounds", openwhisk.ProbeRounds, "No. of probes per idle gap in sweep mode")
+	flag.StringVar(&openwhisk.KneeMode, "kneeMode", openwhisk.KneeMode, "Load to step up while searching the knee: concurrency or rate")
+	flag.Float64Var(&openwhisk.KneeStartLevel, "kneeStart", openwhisk.KneeStartLevel, "Starting concurrency (or rate) of the knee search")
+	flag.Float64Var(&openwhisk.KneeMaxLevel, "kneeMax", openwhisk.KneeMaxLevel, "Maximum concurrency (or rate) of the knee search")
+	flag.Float64Var(&openwhisk.KneeStepFactor, "kneeStepFactor", openwhisk.KneeStepFactor, "Factor by which the load is multiplied at every step")
+	flag.IntVar(&openwhisk.KneeStepDuration, "stepDuration", openwhisk.KneeStepDuration, "Duration (in seconds) of every step of the knee search")
+	flag.IntVar(&openwhisk.KneeWarmup, "stepWarmup", openwhisk.KneeWarmup, "Seconds at the start of every step excluded from its measurements")
+	flag.Float64Var(&openwhisk.KneePlateauGain, "plateauGain", openwhisk.KneePlateauGain, "Stop once a step improves the throughput by less than this fraction")
+	flag.Float64Var(&openwhisk.KneeLatencyFactor, "latencyFactor", openwhisk.KneeLatencyFactor, "Stop once the p99 latency exceeds this multiple of the first step's p99")
+
+	// Flags for the workload generator
+	flag.Int64Var(&generator.Seed, "seed", generator.Seed, "Seed of the generated workload & of the random parameter values (0 picks one)")
+	flag.IntVar(&generator.Users, "users", generator.Users, "No. of users of the generated workload")
+	flag.StringVar(&generator.UserDist, "userDist", generator.UserDist, "Share of the invocations per user: uniform or zipf:S")
+	flag.StringVar(&generator.FuncsPerUser, "funcs", generator.FuncsPerUser, "Functions per user: fixed:N, uniform:MIN-MAX or zipf:S:MAX")
+	flag.StringVar(&generator.InvocationsPerFunc, "invocations", generator.InvocationsPerFunc, "Invocations per function: fixed:N, uniform:MIN-MAX or zipf:S:MAX")
+	flag.IntVar(&generator.Batches, "batches", generator.Batches, "No. of batches of the generated workload")
+	flag.StringVar(&generator.BatchMode, "batchMode", generator.BatchMode, "Assignment of the rows to the batches: random or roundrobin")
+	flag.StringVar(&generator.ParamTemplate, "param", generator.ParamTemplate, "Parameter template, e.g. \"spin {20-26}\", with {a|b} picks, {MIN-MAX} ranges, {user}, {func} & {batch}, a JSON object template is kept as is")
+	flag.BoolVar(&generator.SplitRows, "split", generator.SplitRows, "A row per invocation instead of one per function")
+	flag.IntVar(&generator.SweepPow2, "sweep", generator.SweepPow2, "Generate the 1 to 2^N users sweep of trials/ into a directory instead")
+	flag.IntVar(&generator.SweepTotal, "sweepTotal", generator.SweepTotal, "Total invocations of every file of the sweep")
+
+	// Flags for the analysis of results
+	flag.Float64Var(&analysis.CompareThreshold, "threshold", analysis.CompareThreshold, "Change (in percent) of a metric beyond which compare reports a regression")
+	flag.IntVar(&analysis.BootstrapRounds, "bootstrap", analysis.BootstrapRounds, "No. of bootstrap resamples for the confidence intervals of compare")
 
 	// Flags for docker
 	flag.IntVar(&docker.CheckMemStats, "memCheckInterval", -1, "Check Memory Stats Periodically")
 
 	flag.Parse()
+	openwhisk.ParamSeed = generator.Seed
 
 	if !commons.WriteToFile {
 		*outputFilePath = ""
@@ -49,12 +109,63 @@ func main() {
 	commons.PrintToStdOutOnVerbose("WriteToFile: " + strconv.FormatBool(commons.WriteToFile) + ", FileName: " + *outputFilePath + ", Create: " + strconv.FormatBool(*isCreateFlag) + ", Verbose: " + strconv.FormatBool(commons.Verbose) + ", Debug: " + strconv.FormatBool(commons.Debug) + ", Quiet: " + strconv.FormatBool(*isQuiet) + ", Async: " + strconv.FormatBool(openwhisk.IsAsync))
 	commons.PrintToStdOutOnVerbose("Command: " + argsArr[0])
 
+	flagMap := make(map[string]string)
+	flag.VisitAll(func(flagObj *flag.Flag) {
+		flagMap[flagObj.Name] = flagObj.Value.String()
+	})
+	commons.InitManifest(argsArr, flagMap)
+
+	commons.DescribeBackend = openwhisk.DescribeBackend
+	if strings.Contains(argsArr[0], "Docker") {
+		commons.DescribeBackend = docker.DescribeBackend
+	}
+
 	/* Main Benchmark Methods */
 	switch argsArr[0] {
 	case "execOWCmd":
 		fmt.Println(openwhisk.ExecCmd(argsArr[1:]))
 	case "execOWFile":
-		openwhisk.ExecCmdsFromFile(argsArr[1], *outputFilePath, *isCreateFlag)
+		if openwhisk.Repetitions > 1 {
+			openwhisk.RepeatRuns(argsArr[0], argsArr[1], *outputFilePath, *isCreateFlag)
+		} else {
+			openwhisk.ExecCmdsFromFile(argsArr[1], *outputFilePath, *isCreateFlag)
+		}
+	case "probeKeepAlive":
+		openwhisk.ProbeKeepAlive(argsArr[1], *outputFilePath, *isCreateFlag)
+	case "sweepPayloads":
+		openwhisk.SweepPayloads(*outputFilePath, *isCreateFlag)
+	case "execOWDag":
+		openwhisk.ExecDag(argsArr[1], *outputFilePath, *isCreateFlag)
+	case "fireTriggers":
+		openwhisk.FireTriggers(argsArr[1], *outputFilePath, *isCreateFlag)
+	case "findKnee":
+		openwhisk.FindKnee(argsArr[1], *outputFilePath, *isCreateFlag)
+	case "execOWProfile":
+		if openwhisk.Repetitions > 1 {
+			openwhisk.RepeatRuns(argsArr[0], argsArr[1], *outputFilePath, *isCreateFlag)
+		} else {
+			openwhisk.ExecLoadProfile(argsArr[1], *outputFilePath, *isCreateFlag)
+		}
+	case "run":
+		openwhisk.RunScenario(argsArr[1], *outputFilePath)
+	case "teardown":
+		openwhisk.Teardown(argsArr[1])
+	case "timeline":
+		analysis.Timeline(argsArr[1], *outputFilePath, openwhisk.TimelineBinBy)
+	case "report":
+		analysis.Report(argsArr[1:], analysis.GetReportFilePath(*outputFilePath, argsArr[1:]))
+	case "compare":
+		if analysis.Compare(argsArr[1:], *outputFilePath) {
+			os.Exit(1)
+		}
+	case "suite":
+		openwhisk.RunSuite(argsArr[1], *isCreateFlag)
+	case "generate":
+		generator.Generate(argsArr[1])
+	case "validate":
+		if !openwhisk.ValidateWorkload(argsArr[1]) {
+			os.Exit(1)
+		}
 	case "execDockerCmd":
 		fmt.Println(docker.ExecCmd(argsArr[1:]))
 	case "execDockerFile":
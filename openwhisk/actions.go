@@ -0,0 +1,212 @@
+package openwhisk
+
+import (
+	"../commons"
+	"encoding/base64"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultActionCode = "functions/trial.js"
+const defaultActionTimeout = 300000
+
+var ActionSpecFile string
+
+var kindByExtension = map[string]string{
+	".js":    "nodejs:default",
+	".py":    "python:default",
+	".go":    "go:default",
+	".java":  "java:default",
+	".jar":   "java:default",
+	".php":   "php:default",
+	".rb":    "ruby:default",
+	".swift": "swift:default",
+}
+
+/*
+ActionSpec describes how a function is deployed. Kind defaults to the one matching the extension of the code file,
+setting Image deploys a blackbox (docker) action instead. Memory (MB), Timeout (ms) & Concurrency (activations per
+container) are left to OpenWhisk's limits when not given, except for the timeout which defaults to 300000 like
+ow-bench.sh. A spec with Namespaces is only for those users, and wins over the spec of the function for all of them.
+*/
+type ActionSpec struct {
+	Function    int               `yaml:"function"`
+	Code        string            `yaml:"code"`
+	Kind        string            `yaml:"kind"`
+	Image       string            `yaml:"image"`
+	Memory      int               `yaml:"memory"`
+	Timeout     int               `yaml:"timeout"`
+	Concurrency int               `yaml:"concurrency"`
+	Params      map[string]string `yaml:"params"`
+	Namespaces  []string          `yaml:"namespaces"`
+}
+
+/* a function of a user, the User is empty for the specs of all the users */
+type specKey struct {
+	User     string
+	Function int
+}
+
+func (obj ActionSpec) String() string {
+	return "ActionSpec: Function - " + strconv.Itoa(obj.Function) + ", Code - " + obj.Code + ", Kind - " + obj.Kind + ", Image - " + obj.Image + ", Memory - " + strconv.Itoa(obj.Memory) + ", Timeout - " + strconv.Itoa(obj.Timeout) + ", Concurrency - " + strconv.Itoa(obj.Concurrency)
+}
+
+/* request body of the action, zip & jar archives are sent base64 encoded */
+func (obj ActionSpec) getActionBody() actionBody {
+	var reqBody actionBody
+
+	if obj.Code != "" {
+		codeBytes, err := ioutil.ReadFile(obj.Code)
+		if err != nil {
+			panic(fmt.Errorf("File error - %s", err))
+		}
+
+		extension := strings.ToLower(filepath.Ext(obj.Code))
+		if extension == ".zip" || extension == ".jar" {
+			reqBody.Exec.Code = base64.StdEncoding.EncodeToString(codeBytes)
+			reqBody.Exec.Binary = true
+		} else {
+			reqBody.Exec.Code = string(codeBytes)
+		}
+	}
+
+	reqBody.Exec.Kind = obj.Kind
+	if obj.Image != "" {
+		reqBody.Exec.Kind = "blackbox"
+		reqBody.Exec.Image = obj.Image
+	} else if reqBody.Exec.Kind == "" {
+		kind, ok := kindByExtension[strings.ToLower(filepath.Ext(obj.Code))]
+		if !ok {
+			panic(fmt.Errorf("Cannot guess the kind of %s, set it in the action spec", obj.Code))
+		}
+		reqBody.Exec.Kind = kind
+	}
+
+	reqBody.Limits = &actionLimits{Memory: obj.Memory, Timeout: obj.Timeout, Concurrency: obj.Concurrency}
+	if reqBody.Limits.Timeout == 0 {
+		reqBody.Limits.Timeout = defaultActionTimeout
+	}
+
+	paramKeys := make([]string, 0, len(obj.Params))
+	for key := range obj.Params {
+		paramKeys = append(paramKeys, key)
+	}
+	sort.Strings(paramKeys)
+
+	for _, key := range paramKeys {
+		reqBody.Parameters = append(reqBody.Parameters, keyValue{Key: key, Value: toJsonValue(obj.Params[key])})
+	}
+
+	if InvokeMode == INVOKE_WEB {
+		reqBody.Annotations = getWebAnnotations()
+	}
+
+	return reqBody
+}
+
+func parseActionSpecs(actionSpecFilePath string) []ActionSpec {
+	commons.PrintToStdOutOnVerbose("Parsing Action Specs: " + actionSpecFilePath)
+
+	yamlFile, err := ioutil.ReadFile(actionSpecFilePath)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	var actionSpecs []ActionSpec
+	err = yaml.Unmarshal(yamlFile, &actionSpecs)
+	if err != nil {
+		panic(fmt.Errorf("Unmarshal: %v", err))
+	}
+
+	return actionSpecs
+}
+
+/* the specs by user & function, two specs for the same function of a user can't both be deployed */
+func getSpecMap(actionSpecs []ActionSpec) map[specKey]ActionSpec {
+	specMap := make(map[specKey]ActionSpec)
+	for _, actionSpec := range actionSpecs {
+		userArr := []string{""}
+		if len(actionSpec.Namespaces) > 0 {
+			userArr = nil
+			for _, namespace := range actionSpec.Namespaces {
+				userArr = append(userArr, getUserName(namespace))
+			}
+		}
+
+		for _, user := range userArr {
+			key := specKey{User: user, Function: actionSpec.Function}
+			if _, ok := specMap[key]; ok {
+				if user == "" {
+					user = "all the users"
+				}
+				panic(fmt.Errorf("Invalid action specs - function %d of %s has more than one", actionSpec.Function, user))
+			}
+			specMap[key] = actionSpec
+		}
+	}
+
+	return specMap
+}
+
+/*
+deployFunctions deploys the functions of every user with their spec, the one for the user before the one for all of
+them, the ones without a spec get the default trial function
+*/
+func deployFunctions(usersVsFuncsMap map[string]map[int]struct{}, actionSpecs []ActionSpec) {
+	specMap := getSpecMap(actionSpecs)
+
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+
+	totalFuncsDeployed := 0
+	startTime := time.Now()
+	for user, funcList := range usersVsFuncsMap {
+		for funcName := range funcList {
+			actionSpec, ok := specMap[specKey{User: user, Function: funcName}]
+			if !ok {
+				actionSpec, ok = specMap[specKey{Function: funcName}]
+			}
+			if !ok {
+				actionSpec = ActionSpec{Function: funcName, Code: defaultActionCode}
+			}
+
+			concChan <- 1
+			wgTime.Add(1)
+
+			go func(user string, actionSpec ActionSpec) {
+				deployFunction(user, actionSpec, 5)
+
+				wgTime.Done()
+				<-concChan
+			}(user, actionSpec)
+
+			totalFuncsDeployed++
+		}
+	}
+
+	wgTime.Wait()
+	commons.PrintToStdOutOnVerbose(strconv.Itoa(totalFuncsDeployed) + " functions deployed. Time taken = " + time.Since(startTime).String())
+}
+
+func deployFunction(user string, actionSpec ActionSpec, retryCount int) {
+	counterMtx.Lock()
+	userAuth := userVsAuthMap[user]
+	counterMtx.Unlock()
+
+	err := putAction(userAuth, strconv.Itoa(actionSpec.Function), actionSpec.getActionBody())
+	if err != nil {
+		if retryCount > 0 {
+			time.Sleep(time.Second)
+			deployFunction(user, actionSpec, retryCount-1)
+			return
+		}
+		panic(fmt.Errorf("Deployment error of %s for %s - %s", actionSpec.String(), user, err))
+	}
+
+	commons.PrintToStdOutOnDebug(actionSpec.String() + " deployed for " + user)
+}
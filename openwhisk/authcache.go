@@ -0,0 +1,206 @@
+package openwhisk
+
+import (
+	"../commons"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var AuthCacheFile = ".wsk_auths.json"
+var AuthCacheTTL = 24
+
+/* api host -> user -> auth, so one file serves every deployment we benchmark */
+var authCache = make(map[string]map[string]cachedAuth)
+var isAuthCacheLoaded = false
+
+type cachedAuth struct {
+	Auth    string    `json:"auth"`
+	SavedAt time.Time `json:"savedAt"`
+}
+
+/*
+loadCachedAuths fills userVsAuthMap from the auth cache file, and from ~/.wskprops when the run has a single user.
+Entries older than AuthCacheTTL hours are only used after the API accepts them for the user's namespace, the users
+left out are looked up through wskadmin as before.
+*/
+func loadCachedAuths(uniqueUsersList map[string]struct{}) {
+	if AuthCacheFile == "" {
+		return
+	}
+
+	readAuthCache()
+	hostCache := make(map[string]cachedAuth)
+	for user, entry := range authCache[getAuthCacheHost()] {
+		hostCache[user] = entry
+	}
+
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+	cachedCount := 0
+	for user := range uniqueUsersList {
+		entry, ok := hostCache[user]
+		if _, isLoaded := userVsAuthMap[user]; isLoaded || !ok {
+			continue
+		}
+
+		if time.Since(entry.SavedAt) < time.Duration(AuthCacheTTL)*time.Hour {
+			counterMtx.Lock()
+			userVsAuthMap[user] = entry.Auth
+			cachedCount++
+			counterMtx.Unlock()
+			continue
+		}
+
+		concChan <- 1
+		wgTime.Add(1)
+
+		go func(user string, userAuth string) {
+			if isValidAuth(user, userAuth) {
+				cacheUserAuth(user, userAuth)
+
+				counterMtx.Lock()
+				userVsAuthMap[user] = userAuth
+				cachedCount++
+				counterMtx.Unlock()
+			}
+
+			wgTime.Done()
+			<-concChan
+		}(user, entry.Auth)
+	}
+
+	wgTime.Wait()
+
+	if len(uniqueUsersList) == 1 {
+		for user := range uniqueUsersList {
+			if _, ok := userVsAuthMap[user]; !ok {
+				loadWskPropsAuth(user)
+			}
+		}
+	}
+
+	commons.PrintToStdOutOnVerbose(strconv.Itoa(cachedCount) + " users are loaded from " + AuthCacheFile)
+}
+
+/* the namespace of ~/.wskprops is used when it's the one of the user */
+func loadWskPropsAuth(user string) {
+	wskPropsPath := os.Getenv("WSK_CONFIG_FILE")
+	if wskPropsPath == "" {
+		wskPropsPath = filepath.Join(os.Getenv("HOME"), ".wskprops")
+	}
+
+	fread, err := os.Open(wskPropsPath)
+	if err != nil {
+		return
+	}
+	defer fread.Close()
+
+	scanner := bufio.NewScanner(fread)
+	for scanner.Scan() {
+		lineParts := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(lineParts) != 2 || lineParts[0] != "AUTH" {
+			continue
+		}
+
+		userAuth := strings.TrimSpace(lineParts[1])
+		if isValidAuth(user, userAuth) {
+			userVsAuthMap[user] = userAuth
+			cacheUserAuth(user, userAuth)
+			commons.PrintToStdOutOnVerbose(user + " is loaded from " + wskPropsPath)
+		}
+	}
+}
+
+/* the auth is valid when OpenWhisk accepts it and lists the user's namespace for it */
+func isValidAuth(user string, userAuth string) bool {
+	statusCode, respBody, err := doApiRequest("GET", "/api/v1/namespaces", userAuth, nil)
+	if err != nil || statusCode != http.StatusOK {
+		return false
+	}
+
+	var namespaces []string
+	if err := json.Unmarshal(respBody, &namespaces); err != nil {
+		return false
+	}
+
+	return commons.ValueInSlice(user, namespaces)
+}
+
+/* needs to be read before the first user is cached, so the entries saved by the other runs are kept */
+func readAuthCache() {
+	if isAuthCacheLoaded || AuthCacheFile == "" {
+		return
+	}
+	isAuthCacheLoaded = true
+
+	cacheBytes, err := ioutil.ReadFile(AuthCacheFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			panic(fmt.Errorf("File error - %s", err))
+		}
+		return
+	}
+
+	err = json.Unmarshal(cacheBytes, &authCache)
+	if err != nil {
+		panic(fmt.Errorf("Corrupt auth cache %s - %s", AuthCacheFile, err))
+	}
+}
+
+/*
+getAuthCacheHost is the host the invocations go to: ow-bench.sh talks to WSK_HOST whatever -apihost says, so the cli &
+asynchronous invocations are keyed by it, the native & web ones by ApiHost
+*/
+func getAuthCacheHost() string {
+	if InvokeMode == INVOKE_CLI || IsAsync {
+		return getDefaultApiHost()
+	}
+
+	return ApiHost
+}
+
+func cacheUserAuth(user string, userAuth string) {
+	if AuthCacheFile == "" {
+		return
+	}
+
+	apiHost := getAuthCacheHost()
+	counterMtx.Lock()
+	if _, ok := authCache[apiHost]; !ok {
+		authCache[apiHost] = make(map[string]cachedAuth)
+	}
+	authCache[apiHost][user] = cachedAuth{Auth: userAuth, SavedAt: time.Now()}
+	counterMtx.Unlock()
+}
+
+func uncacheUserAuth(user string) {
+	counterMtx.Lock()
+	delete(authCache[getAuthCacheHost()], user)
+	counterMtx.Unlock()
+}
+
+/* the credentials are written readable by the owner only */
+func saveAuthCache() {
+	if AuthCacheFile == "" {
+		return
+	}
+
+	counterMtx.Lock()
+	cacheBytes, err := json.MarshalIndent(authCache, "", "  ")
+	counterMtx.Unlock()
+	if err != nil {
+		panic(err)
+	}
+
+	err = ioutil.WriteFile(AuthCacheFile, cacheBytes, 0600)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+}
@@ -0,0 +1,307 @@
+package openwhisk
+
+import (
+	"../commons"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const CHECK_OK = "ok"
+const CHECK_FAILED = "failed"
+
+var ResultCheckFile string
+
+/* function id -> checks of its results, nil until loaded */
+var funcVsCheckMap map[string]resultCheck
+
+var arithmeticTokenRegex = regexp.MustCompile(`\d+(?:\.\d+)?|[A-Za-z_]\w*|[-+*/^()]|\S`)
+var checkExprRegex = regexp.MustCompile(`^\s*([A-Za-z0-9_.]+)\s*(==|!=|<=|>=|<|>)\s*(.+?)\s*$`)
+
+/*
+ResultCheck lists what the results of a function are expected to be. Every Expect is a "path op value" expression on
+the JSON result, like `done == true` or `c == 2^spin-1`: the path goes down the objects & arrays of the result with
+dots (`items.0.id`), the op is one of == != < <= > >=, the value is a JSON literal or an arithmetic expression
+(+ - * / ^ & parentheses) over numbers & the parameters of the invocation. Match is a regex the JSON result has to
+match.
+*/
+type ResultCheck struct {
+	Function int      `yaml:"function"`
+	Expect   []string `yaml:"expect"`
+	Match    string   `yaml:"match"`
+}
+
+type checkExpr struct {
+	Expr  string
+	Path  []string
+	Op    string
+	Value string
+}
+
+type resultCheck struct {
+	Exprs []checkExpr
+	Match *regexp.Regexp
+}
+
+/* parse the result checks once & add the check status column to the results, no-op without a check file */
+func loadResultChecks() {
+	if ResultCheckFile == "" || funcVsCheckMap != nil {
+		return
+	}
+
+	commons.PrintToStdOutOnVerbose("Parsing Result Checks: " + ResultCheckFile)
+
+	yamlFile, err := ioutil.ReadFile(ResultCheckFile)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	var resultChecks []ResultCheck
+	err = yaml.Unmarshal(yamlFile, &resultChecks)
+	if err != nil {
+		panic(fmt.Errorf("Unmarshal: %v", err))
+	}
+
+	funcVsCheckMap = make(map[string]resultCheck)
+	for _, resultCheckObj := range resultChecks {
+		var compiledCheck resultCheck
+		for _, expr := range resultCheckObj.Expect {
+			exprParts := checkExprRegex.FindStringSubmatch(expr)
+			if exprParts == nil {
+				panic(fmt.Errorf("Invalid result check of function %d - %s", resultCheckObj.Function, expr))
+			}
+			compiledCheck.Exprs = append(compiledCheck.Exprs, checkExpr{Expr: expr, Path: strings.Split(exprParts[1], "."), Op: exprParts[2], Value: exprParts[3]})
+		}
+
+		if resultCheckObj.Match != "" {
+			compiledCheck.Match, err = regexp.Compile(resultCheckObj.Match)
+			if err != nil {
+				panic(fmt.Errorf("Invalid result check of function %d - %s", resultCheckObj.Function, err))
+			}
+		}
+
+		funcVsCheckMap[strconv.Itoa(resultCheckObj.Function)] = compiledCheck
+	}
+
+	if IsAsync {
+		commons.PrintToStdOutOnVerbose("The results of asynchronous invocations aren't checked")
+	}
+
+	if !commons.ValueInSlice(commons.CHECK_STATUS, orderArr) {
+		orderArr = append(orderArr, commons.CHECK_STATUS)
+	}
+}
+
+/*
+checkResult checks the result of a completed invocation against the checks of its function, after it was timed. The
+native & web invocations come with the result of the action, in the cli mode it's read from the activation record.
+Returns the check status, empty when the result isn't checked (no checks, asynchronous or failed invocation).
+*/
+func checkResult(userAuth string, functionID string, param string, status string, execResult string, result json.RawMessage, isAsync bool) string {
+	resultCheckObj, ok := funcVsCheckMap[functionID]
+	if !ok || isAsync || status != "1" {
+		return ""
+	}
+
+	if result == nil {
+		activationID := strings.Split(execResult, ", ")[0]
+		_, _, activation := getActivationNative(userAuth, activationID)
+		if activation.ActivationID == "" {
+			commons.PrintToStdOutOnDebug("Result check of function " + functionID + " failed - no activation " + activationID)
+			return CHECK_FAILED
+		}
+		result = activation.Response.Result
+	}
+
+	if err := resultCheckObj.check(result, getParamValues(param)); err != nil {
+		commons.PrintToStdOutOnDebug("Result check of function " + functionID + " failed - " + err.Error())
+		return CHECK_FAILED
+	}
+
+	return CHECK_OK
+}
+
+func (obj resultCheck) check(result json.RawMessage, paramMap map[string]interface{}) error {
+	if obj.Match != nil && !obj.Match.Match(result) {
+		return fmt.Errorf("%s doesn't match %s", string(result), obj.Match.String())
+	}
+
+	var resultValue interface{}
+	if len(obj.Exprs) > 0 {
+		if err := json.Unmarshal(result, &resultValue); err != nil {
+			return fmt.Errorf("the result isn't JSON - %s", err)
+		}
+	}
+
+	for _, expr := range obj.Exprs {
+		if err := expr.check(resultValue, paramMap); err != nil {
+			return fmt.Errorf("%s: %s", expr.Expr, err)
+		}
+	}
+
+	return nil
+}
+
+func (obj checkExpr) check(resultValue interface{}, paramMap map[string]interface{}) error {
+	actualValue, err := getPathValue(resultValue, obj.Path)
+	if err != nil {
+		return err
+	}
+
+	var expectedValue interface{}
+	if err := json.Unmarshal([]byte(obj.Value), &expectedValue); err != nil {
+		if expectedValue, err = evalArithmetic(obj.Value, paramMap); err != nil {
+			return err
+		}
+	}
+
+	expectedNumber, isExpectedNumber := expectedValue.(float64)
+	actualNumber, isActualNumber := actualValue.(float64)
+	if isExpectedNumber && isActualNumber {
+		isValid := map[string]bool{
+			"==": actualNumber == expectedNumber,
+			"!=": actualNumber != expectedNumber,
+			"<":  actualNumber < expectedNumber,
+			"<=": actualNumber <= expectedNumber,
+			">":  actualNumber > expectedNumber,
+			">=": actualNumber >= expectedNumber,
+		}[obj.Op]
+		if !isValid {
+			return fmt.Errorf("got %v", actualNumber)
+		}
+		return nil
+	}
+
+	if obj.Op != "==" && obj.Op != "!=" {
+		return fmt.Errorf("%v & %v can't be compared with %s", actualValue, expectedValue, obj.Op)
+	}
+
+	actualBytes, _ := json.Marshal(actualValue)
+	expectedBytes, _ := json.Marshal(expectedValue)
+	if (string(actualBytes) == string(expectedBytes)) != (obj.Op == "==") {
+		return fmt.Errorf("got %s", string(actualBytes))
+	}
+
+	return nil
+}
+
+func getPathValue(value interface{}, pathArr []string) (interface{}, error) {
+	for _, key := range pathArr {
+		switch typedValue := value.(type) {
+		case map[string]interface{}:
+			nestedValue, ok := typedValue[key]
+			if !ok {
+				return nil, fmt.Errorf("no %s in the result", key)
+			}
+			value = nestedValue
+		case []interface{}:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(typedValue) {
+				return nil, fmt.Errorf("no item %s in the result", key)
+			}
+			value = typedValue[idx]
+		default:
+			return nil, fmt.Errorf("no %s in the result", key)
+		}
+	}
+
+	return value, nil
+}
+
+/* the parameters of an invocation, either a JSON object or a single "key value" pair */
+func getParamValues(param string) map[string]interface{} {
+	paramMap := make(map[string]interface{})
+	if isParamTemplate(param) {
+		json.Unmarshal([]byte(param), &paramMap)
+	} else if paramParts := strings.Fields(param); len(paramParts) == 2 {
+		paramMap[paramParts[0]] = toJsonValue(paramParts[1])
+	}
+
+	return paramMap
+}
+
+/* evaluate an arithmetic expression with + - * / ^ (right associative) & parentheses, names are numeric parameters */
+func evalArithmetic(expr string, paramMap map[string]interface{}) (float64, error) {
+	tokenArr := arithmeticTokenRegex.FindAllString(expr, -1)
+	pos := 0
+
+	var parseSum, parseProduct, parsePower, parseOperand func() (float64, error)
+	parseSum = func() (float64, error) {
+		left, err := parseProduct()
+		for err == nil && pos < len(tokenArr) && (tokenArr[pos] == "+" || tokenArr[pos] == "-") {
+			op := tokenArr[pos]
+			pos++
+			var right float64
+			if right, err = parseProduct(); op == "+" {
+				left += right
+			} else {
+				left -= right
+			}
+		}
+		return left, err
+	}
+	parseProduct = func() (float64, error) {
+		left, err := parsePower()
+		for err == nil && pos < len(tokenArr) && (tokenArr[pos] == "*" || tokenArr[pos] == "/") {
+			op := tokenArr[pos]
+			pos++
+			var right float64
+			if right, err = parsePower(); op == "*" {
+				left *= right
+			} else {
+				left /= right
+			}
+		}
+		return left, err
+	}
+	parsePower = func() (float64, error) {
+		base, err := parseOperand()
+		if err == nil && pos < len(tokenArr) && tokenArr[pos] == "^" {
+			pos++
+			var exponent float64
+			exponent, err = parsePower()
+			base = math.Pow(base, exponent)
+		}
+		return base, err
+	}
+	parseOperand = func() (float64, error) {
+		if pos >= len(tokenArr) {
+			return 0, fmt.Errorf("incomplete expression %s", expr)
+		}
+
+		token := tokenArr[pos]
+		pos++
+		switch {
+		case token == "-":
+			value, err := parseOperand()
+			return -value, err
+		case token == "(":
+			value, err := parseSum()
+			if err == nil && (pos >= len(tokenArr) || tokenArr[pos] != ")") {
+				return 0, fmt.Errorf("missing ) in %s", expr)
+			}
+			pos++
+			return value, err
+		case token[0] >= '0' && token[0] <= '9':
+			return strconv.ParseFloat(token, 64)
+		}
+
+		value, ok := paramMap[token].(float64)
+		if !ok {
+			return 0, fmt.Errorf("no numeric parameter %s", token)
+		}
+		return value, nil
+	}
+
+	value, err := parseSum()
+	if err == nil && pos < len(tokenArr) {
+		return 0, fmt.Errorf("unexpected %s in %s", tokenArr[pos], expr)
+	}
+
+	return value, err
+}
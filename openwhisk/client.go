@@ -0,0 +1,263 @@
+package openwhisk
+
+import (
+	"../commons"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var ApiHost = getDefaultApiHost()
+
+/* same as `wsk -i`, the benchmark deployments usually run with self signed certificates */
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+		MaxIdleConnsPerHost: 1024,
+	},
+}
+
+type keyValue struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+type actionExec struct {
+	Kind   string `json:"kind"`
+	Code   string `json:"code,omitempty"`
+	Image  string `json:"image,omitempty"`
+	Binary bool   `json:"binary,omitempty"`
+
+	Components []string `json:"components,omitempty"`
+}
+
+type actionLimits struct {
+	Memory      int `json:"memory,omitempty"`
+	Timeout     int `json:"timeout,omitempty"`
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+type actionBody struct {
+	Exec        actionExec    `json:"exec"`
+	Limits      *actionLimits `json:"limits,omitempty"`
+	Parameters  []keyValue    `json:"parameters,omitempty"`
+	Annotations []keyValue    `json:"annotations,omitempty"`
+}
+
+/* ow-bench.sh talks to the controller directly, so do we unless WSK_HOST says otherwise */
+func getDefaultApiHost() string {
+	if apiHost := os.Getenv("WSK_HOST"); apiHost != "" {
+		return apiHost
+	}
+
+	return "http://172.17.0.1:10001"
+}
+
+/* send a request to the OpenWhisk REST API & return the status code with the response body */
+func doApiRequest(method string, path string, userAuth string, reqBody interface{}) (int, []byte, error) {
+	var reqBytes []byte
+	if reqBody != nil {
+		var err error
+		reqBytes, err = json.Marshal(reqBody)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return doApiRequestBytes(method, path, userAuth, reqBytes)
+}
+
+/* same as doApiRequest with a request body that's already JSON encoded */
+func doApiRequestBytes(method string, path string, userAuth string, reqBytes []byte) (int, []byte, error) {
+	statusCode, respBody, _, err := doApiRequestTraced(method, path, userAuth, reqBytes)
+	return statusCode, respBody, err
+}
+
+/* same as doApiRequestBytes, along with the client side timing of the request */
+func doApiRequestTraced(method string, path string, userAuth string, reqBytes []byte) (int, []byte, httpTiming, error) {
+	req, err := http.NewRequest(method, strings.TrimSuffix(ApiHost, "/")+path, bytes.NewReader(reqBytes))
+	if err != nil {
+		return 0, nil, httpTiming{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if userAuth != "" {
+		authParts := strings.SplitN(userAuth, ":", 2)
+		if len(authParts) != 2 {
+			return 0, nil, httpTiming{}, fmt.Errorf("Invalid auth - %s", userAuth)
+		}
+		req.SetBasicAuth(authParts[0], authParts[1])
+	}
+
+	resp, respBody, timing, err := doTracedRequest(req)
+	if err != nil {
+		return 0, nil, timing, err
+	}
+
+	return resp.StatusCode, respBody, timing, nil
+}
+
+/* client side timing of a request, the DNS, connect & TLS times are 0 when a kept alive connection is reused */
+type httpTiming struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration
+	Total   time.Duration
+}
+
+/* send the request & read the whole response body, traced from the start of the request to the end of the body */
+func doTracedRequest(req *http.Request) (*http.Response, []byte, httpTiming, error) {
+	var timing httpTiming
+	var dnsStart, connectStart, tlsStart time.Time
+	start := time.Now()
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { timing.DNS = time.Since(dnsStart) },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { timing.Connect = time.Since(connectStart) },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { timing.TLS = time.Since(tlsStart) },
+		GotFirstResponseByte: func() { timing.TTFB = time.Since(start) },
+	}
+
+	resp, err := httpClient.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err != nil {
+		timing.Total = time.Since(start)
+		return nil, nil, timing, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	timing.Total = time.Since(start)
+	return resp, respBody, timing, err
+}
+
+/* create or update the action of the spec in the namespace of the auth */
+func putAction(userAuth string, actionName string, reqBody actionBody) error {
+	statusCode, respBody, err := doApiRequest("PUT", "/api/v1/namespaces/_/actions/"+actionName+"?overwrite=true", userAuth, reqBody)
+	if err != nil {
+		return err
+	}
+
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("OpenWhisk error - %d %s", statusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+/* parameter values are parsed as JSON like `wsk -p` does, anything else is passed on as a string */
+func toJsonValue(value string) interface{} {
+	var jsonValue interface{}
+	if err := json.Unmarshal([]byte(value), &jsonValue); err == nil {
+		return jsonValue
+	}
+
+	return value
+}
+
+type activationRecord struct {
+	ActivationID string     `json:"activationId"`
+	Start        int64      `json:"start"`
+	End          int64      `json:"end"`
+	Duration     int        `json:"duration"`
+	Annotations  []keyValue `json:"annotations"`
+	Logs         []string   `json:"logs"`
+	Response     struct {
+		Status  string          `json:"status"`
+		Success bool            `json:"success"`
+		Result  json.RawMessage `json:"result"`
+	} `json:"response"`
+}
+
+/*
+invokeActionNative invokes the action (blocking) through the REST API instead of ow-bench.sh. Returns the status & the
+"activation id, wait, init, run" result like ow-bench.sh does, along with the sizes of the request & response bodies
+and the result of the action.
+*/
+func invokeActionNative(userAuth string, actionName string, param string) (string, string, int, int, json.RawMessage) {
+	status, execResult, reqBytesCount, respBytesCount, result, _ := invokeActionTraced(userAuth, actionName, param)
+	return status, execResult, reqBytesCount, respBytesCount, result
+}
+
+/* same as invokeActionNative, along with the client side timing of the invocation */
+func invokeActionTraced(userAuth string, actionName string, param string) (string, string, int, int, json.RawMessage, httpTiming) {
+	reqBytes := getParamBody(param)
+
+	atomic.AddInt32(&inFlightCount, 1)
+	statusCode, respBody, timing, err := doApiRequestTraced("POST", "/api/v1/namespaces/_/actions/"+actionName+"?blocking=true", userAuth, reqBytes)
+	atomic.AddInt32(&inFlightCount, -1)
+	if err != nil {
+		commons.PrintToStdOutOnDebug("Invocation error of " + actionName + " - " + err.Error())
+		return "0", "none, 0, 0, 0", len(reqBytes), 0, nil, timing
+	}
+
+	var activation activationRecord
+	if err := json.Unmarshal(respBody, &activation); err != nil || activation.ActivationID == "" {
+		commons.PrintToStdOutOnDebug("Invocation error of " + actionName + " - " + strconv.Itoa(statusCode) + " " + strings.TrimSpace(string(respBody)))
+		return "0", "none, 0, 0, 0", len(reqBytes), len(respBody), nil, timing
+	}
+
+	status := "1"
+	if statusCode != http.StatusOK || !activation.Response.Success {
+		status = "0"
+	}
+
+	return status, activation.getExecResult(), len(reqBytes), len(respBody), activation.Response.Result, timing
+}
+
+/* the JSON body of an invocation, a "key value" param is sent as a JSON object like `wsk -p` does */
+func getParamBody(param string) []byte {
+	if isParamTemplate(param) {
+		return []byte(param)
+	}
+
+	return []byte(toJsonString(getParamValues(param)))
+}
+
+/* "activation id, wait, init, run" of the activation, like ow-bench.sh reports it */
+func (obj activationRecord) getExecResult() string {
+	waitTime, initTime := 0, 0
+	for _, annotation := range obj.Annotations {
+		if value, ok := annotation.Value.(float64); ok {
+			switch annotation.Key {
+			case "waitTime":
+				waitTime = int(value)
+			case "initTime":
+				initTime = int(value)
+			}
+		}
+	}
+
+	return obj.ActivationID + ", " + strconv.Itoa(waitTime) + ", " + strconv.Itoa(initTime) + ", " + strconv.Itoa(obj.Duration-initTime)
+}
+
+/* the backend of the run manifest, with the build details the controller reports on /api/v1 */
+func DescribeBackend() commons.BackendInfo {
+	backendInfo := commons.BackendInfo{Name: "openwhisk", ApiHost: ApiHost}
+
+	statusCode, respBody, err := doApiRequest("GET", "/api/v1", "", nil)
+	if err != nil || statusCode != http.StatusOK {
+		commons.PrintToStdOutOnDebug("No build info from " + ApiHost)
+		return backendInfo
+	}
+
+	var apiInfo map[string]interface{}
+	if err := json.Unmarshal(respBody, &apiInfo); err == nil {
+		delete(apiInfo, "api_paths")
+		backendInfo.Build = apiInfo
+	}
+
+	return backendInfo
+}
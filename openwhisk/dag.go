@@ -0,0 +1,528 @@
+package openwhisk
+
+import (
+	"../commons"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DAG_CLIENT = "client"
+const DAG_SEQUENCE = "sequence"
+
+var dagOrderArr = []string{commons.DAG_INSTANCE, commons.STEP, commons.STEP_COPY, commons.USER_ID, commons.FUNCTION_ID, commons.SEQ, commons.CMD_RESULT, commons.ELAPSED_TIME, commons.ELAPSED_TIME_SINCE_START, commons.SUBMITTED_AT, commons.ENDED_AT, commons.CMD_STATUS, commons.PARAMETER}
+var dagInstanceOrderArr = []string{commons.DAG_INSTANCE, commons.USER_ID, commons.STEPS, commons.ERRORS, commons.ELAPSED_TIME, commons.SUBMITTED_AT, commons.ENDED_AT, commons.CMD_STATUS}
+
+/*
+DagSpec describes a workload of function compositions. Instances of the DAG run Concurrency at a time (-cf by default),
+round robin across the Users. In "client" mode the harness runs the steps itself: a step starts once all the copies of
+the steps it comes After are done, and runs its Copies in parallel (fan-out, the next step joins them). In "sequence"
+mode the steps, a chain of single copies, are deployed as the OpenWhisk sequence Name & the DAG is a single invocation.
+*/
+type DagSpec struct {
+	Name        string    `yaml:"name"`
+	Mode        string    `yaml:"mode"`
+	Users       []string  `yaml:"users"`
+	Instances   int       `yaml:"instances"`
+	Concurrency int       `yaml:"concurrency"`
+	Steps       []DagStep `yaml:"steps"`
+}
+
+/*
+DagStep invokes the Function with Param, a JSON parameter can use the templates of the workload files. With Feed the
+result of the step it comes after is merged into the parameter (Param wins on a clash), the results of several steps
+or copies are passed as an "inputs" array instead. A sequence always feeds the result of a step to the next one.
+*/
+type DagStep struct {
+	Name     string   `yaml:"name"`
+	Function int      `yaml:"function"`
+	Param    string   `yaml:"param"`
+	After    []string `yaml:"after"`
+	Copies   int      `yaml:"copies"`
+	Feed     bool     `yaml:"feed"`
+}
+
+/* outcome of a DAG instance, from the first submission to the last completion of its steps */
+type dagInstance struct {
+	ID          int
+	UserID      string
+	SubmittedAt int64
+	EndedAt     int64
+	Steps       int
+	Errors      int
+}
+
+/*
+ExecDag runs the DAG file, writing a row per step (copy) invocation sharing the DAG instance id, and the end to end
+latency of every instance to _dag.csv.
+*/
+func ExecDag(dagFilePath string, outputFilePath string, needCreation bool) {
+	runStartedAt := time.Now()
+	dagSpec := parseDagSpec(dagFilePath)
+
+	var exists = struct{}{}
+	uniqueUsersList := make(map[string]struct{})
+	usersVsFuncsMap := getDagFunctions(dagSpec)
+	for user := range usersVsFuncsMap {
+		uniqueUsersList[user] = exists
+	}
+	doInitialization(needCreation, uniqueUsersList, usersVsFuncsMap)
+
+	if dagSpec.Mode == DAG_SEQUENCE {
+		deploySequences(dagSpec)
+	}
+
+	commons.PrintToStdOutOnVerbose("Running " + strconv.Itoa(dagSpec.Instances) + " instances of " + dagSpec.Name + " (" + dagSpec.Mode + ") across " + strconv.Itoa(dagSpec.Concurrency) + " co-routines:")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+	if outputFilePath != "" {
+		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
+	}
+
+	commons.PrintHeader(dagOrderArr, outputFilePath)
+
+	var concChan = make(chan int, dagSpec.Concurrency)
+	instanceArr := make([]dagInstance, dagSpec.Instances)
+
+	startRun = time.Now()
+	for instanceID := 0; instanceID < dagSpec.Instances; instanceID++ {
+		concChan <- 1
+		wgTime.Add(1)
+
+		go func(instanceID int) {
+			user := dagSpec.Users[instanceID%len(dagSpec.Users)]
+			if dagSpec.Mode == DAG_SEQUENCE {
+				instanceArr[instanceID] = runSequenceInstance(dagSpec, instanceID, user)
+			} else {
+				instanceArr[instanceID] = runDagInstance(dagSpec, instanceID, user)
+			}
+
+			wgTime.Done()
+			<-concChan
+		}(instanceID)
+	}
+
+	wgTime.Wait()
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	commons.PrintToStdOutOnVerbose("DAG instances completed in " + time.Since(startRun).String())
+	printSummary()
+	commons.OutputFileWriter.Close()
+
+	writeDagInstances(outputFilePath, instanceArr)
+	commons.WriteManifest(outputFilePath, dagFilePath, runStartedAt)
+
+	doTeardown(usersVsFuncsMap, TeardownPolicy, getSequenceEntities(dagSpec)...)
+}
+
+/* the functions of the steps, for every user of the DAG */
+func getDagFunctions(dagSpec DagSpec) map[string]map[int]struct{} {
+	var exists = struct{}{}
+	usersVsFuncsMap := make(map[string]map[int]struct{})
+	for _, user := range dagSpec.Users {
+		usersVsFuncsMap[user] = make(map[int]struct{})
+		for _, step := range dagSpec.Steps {
+			usersVsFuncsMap[user][step.Function] = exists
+		}
+	}
+
+	return usersVsFuncsMap
+}
+
+/* the sequence action Name every user of a "sequence" DAG has, torn down before its functions */
+func getSequenceEntities(dagSpec DagSpec) []teardownEntities {
+	if dagSpec.Mode != DAG_SEQUENCE {
+		return nil
+	}
+
+	userVsPathsMap := make(map[string][]string)
+	for _, user := range dagSpec.Users {
+		userVsPathsMap[user] = []string{"actions/" + dagSpec.Name}
+	}
+
+	return []teardownEntities{{Kind: "Sequences", UserVsPathsMap: userVsPathsMap}}
+}
+
+func parseDagSpec(dagFilePath string) DagSpec {
+	commons.PrintToStdOutOnVerbose("Parsing DAG: " + dagFilePath)
+
+	yamlFile, err := ioutil.ReadFile(dagFilePath)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	var dagSpec DagSpec
+	err = yaml.Unmarshal(yamlFile, &dagSpec)
+	if err != nil {
+		panic(fmt.Errorf("Unmarshal: %v", err))
+	}
+
+	if dagSpec.Name == "" {
+		dagSpec.Name = "dag"
+	}
+	if dagSpec.Mode == "" {
+		dagSpec.Mode = DAG_CLIENT
+	}
+	if len(dagSpec.Users) == 0 {
+		dagSpec.Users = []string{getUserName("0")}
+	}
+	if dagSpec.Instances <= 0 {
+		dagSpec.Instances = 1
+	}
+	if dagSpec.Concurrency <= 0 {
+		dagSpec.Concurrency = commons.ConcurrencyFactor
+	}
+
+	if dagSpec.Mode != DAG_CLIENT && dagSpec.Mode != DAG_SEQUENCE {
+		panic(fmt.Errorf("Invalid DAG mode - %s", dagSpec.Mode))
+	}
+	if len(dagSpec.Steps) == 0 {
+		panic(fmt.Errorf("No steps in %s", dagFilePath))
+	}
+
+	/* the steps are listed in order, a step can only come after the ones above it */
+	seenSteps := make(map[string]struct{})
+	for idx := range dagSpec.Steps {
+		step := &dagSpec.Steps[idx]
+		if step.Name == "" {
+			step.Name = strconv.Itoa(idx)
+		}
+		if step.Copies <= 0 {
+			step.Copies = 1
+		}
+		if _, ok := seenSteps[step.Name]; ok {
+			panic(fmt.Errorf("Invalid DAG - step %s is there twice", step.Name))
+		}
+		for _, prevStep := range step.After {
+			if _, ok := seenSteps[prevStep]; !ok {
+				panic(fmt.Errorf("Invalid DAG - step %s comes after %s, which isn't a step above it", step.Name, prevStep))
+			}
+		}
+		if step.Param != "" && isParamTemplate(step.Param) {
+			if _, err := parseParamTemplate(step.Param); err != nil {
+				panic(fmt.Errorf("Invalid DAG - step %s: %s", step.Name, err))
+			}
+		}
+
+		if dagSpec.Mode == DAG_SEQUENCE {
+			isChained := (idx == 0 && len(step.After) == 0) || (idx > 0 && len(step.After) == 1 && step.After[0] == dagSpec.Steps[idx-1].Name)
+			if !isChained || step.Copies != 1 {
+				panic(fmt.Errorf("Invalid DAG - a sequence is a chain of single steps, step %s isn't", step.Name))
+			}
+			if idx > 0 && step.Param != "" {
+				commons.PrintToStdOutOnVerbose("The param of step " + step.Name + " is left out, a sequence only passes the param of its first step")
+			}
+		}
+
+		seenSteps[step.Name] = struct{}{}
+	}
+
+	return dagSpec
+}
+
+/* one invocation of a step copy */
+type stepResult struct {
+	Status string
+	Result json.RawMessage
+}
+
+func runDagInstance(dagSpec DagSpec, instanceID int, user string) dagInstance {
+	instance := dagInstance{ID: instanceID, UserID: user, SubmittedAt: time.Now().UnixNano()}
+
+	var resultMtx sync.Mutex
+	stepVsResultsMap := make(map[string][]stepResult)
+	stepVsDoneMap := make(map[string]chan struct{})
+	for _, step := range dagSpec.Steps {
+		stepVsDoneMap[step.Name] = make(chan struct{})
+	}
+
+	/* the invocations of an instance get consecutive seqs, in the order of the steps & their copies */
+	seqOffset := instanceID * getDagInvocationCount(dagSpec)
+
+	var wgSteps sync.WaitGroup
+	for _, step := range dagSpec.Steps {
+		wgSteps.Add(1)
+
+		go func(seqOffset int, step DagStep) {
+			defer wgSteps.Done()
+			defer close(stepVsDoneMap[step.Name])
+
+			var inputArr []json.RawMessage
+			for _, prevStep := range step.After {
+				<-stepVsDoneMap[prevStep]
+
+				resultMtx.Lock()
+				prevResultArr := stepVsResultsMap[prevStep]
+				resultMtx.Unlock()
+
+				/* a step whose inputs failed (or were skipped) is skipped */
+				if len(prevResultArr) == 0 {
+					return
+				}
+				for _, prevResult := range prevResultArr {
+					if prevResult.Status != "1" {
+						return
+					}
+					inputArr = append(inputArr, prevResult.Result)
+				}
+			}
+
+			var wgCopies sync.WaitGroup
+			resultArr := make([]stepResult, step.Copies)
+			for copyIdx := 0; copyIdx < step.Copies; copyIdx++ {
+				wgCopies.Add(1)
+
+				go func(copyIdx int) {
+					seq := seqOffset + copyIdx
+					param, recordedParam := expandParam(step.Param, seq, user)
+					if step.Feed {
+						param = feedParam(param, inputArr)
+					}
+
+					resultMap := make(map[string]string)
+					resultMap[commons.DAG_INSTANCE] = strconv.Itoa(instanceID)
+					resultMap[commons.STEP] = step.Name
+					resultMap[commons.STEP_COPY] = strconv.Itoa(copyIdx)
+					resultMap[commons.USER_ID] = user
+					resultMap[commons.FUNCTION_ID] = strconv.Itoa(step.Function)
+					resultMap[commons.SEQ] = strconv.Itoa(seq)
+					resultMap[commons.PARAMETER] = recordedParam
+
+					start := time.Now().UnixNano()
+					status, execResult, _, _, result := invokeActionNative(userVsAuthMap[user], strconv.Itoa(step.Function), param)
+					end := time.Now().UnixNano()
+
+					resultMap[commons.CMD_STATUS] = status
+					resultMap[commons.CMD_RESULT] = execResult
+					resultMap[commons.SUBMITTED_AT] = strconv.FormatInt(start, 10)
+					resultMap[commons.ENDED_AT] = strconv.FormatInt(end, 10)
+					resultMap[commons.ELAPSED_TIME] = strconv.FormatInt((end-start)/1000000, 10)
+					writeDagRow(resultMap)
+
+					resultArr[copyIdx] = stepResult{Status: status, Result: result}
+					wgCopies.Done()
+				}(copyIdx)
+			}
+			wgCopies.Wait()
+
+			resultMtx.Lock()
+			stepVsResultsMap[step.Name] = resultArr
+			resultMtx.Unlock()
+		}(seqOffset, step)
+		seqOffset += step.Copies
+	}
+
+	wgSteps.Wait()
+	instance.EndedAt = time.Now().UnixNano()
+
+	for _, step := range dagSpec.Steps {
+		for _, result := range stepVsResultsMap[step.Name] {
+			instance.Steps++
+			if result.Status != "1" {
+				instance.Errors++
+			}
+		}
+		if _, ok := stepVsResultsMap[step.Name]; !ok {
+			/* skipped after a failed step */
+			instance.Errors += step.Copies
+		}
+	}
+
+	return instance
+}
+
+func getDagInvocationCount(dagSpec DagSpec) int {
+	invocationCount := 0
+	for _, step := range dagSpec.Steps {
+		invocationCount += step.Copies
+	}
+
+	return invocationCount
+}
+
+/* the result of a single step is merged into the param, the results of several are passed as "inputs" */
+func feedParam(param string, inputArr []json.RawMessage) string {
+	if len(inputArr) == 0 {
+		return param
+	}
+
+	paramMap := make(map[string]interface{})
+	if len(inputArr) == 1 {
+		json.Unmarshal(inputArr[0], &paramMap)
+	} else {
+		paramMap["inputs"] = inputArr
+	}
+
+	for key, value := range getParamValues(param) {
+		paramMap[key] = value
+	}
+
+	return toJsonString(paramMap)
+}
+
+/* the sequence of the steps for every user, the components are the functions in the user's namespace */
+func deploySequences(dagSpec DagSpec) {
+	for _, user := range dagSpec.Users {
+		var reqBody actionBody
+		reqBody.Exec.Kind = "sequence"
+		for _, step := range dagSpec.Steps {
+			reqBody.Exec.Components = append(reqBody.Exec.Components, "/"+user+"/"+strconv.Itoa(step.Function))
+		}
+
+		if err := putAction(userVsAuthMap[user], dagSpec.Name, reqBody); err != nil {
+			panic(fmt.Errorf("Deployment error of sequence %s for %s - %s", dagSpec.Name, user, err))
+		}
+	}
+
+	commons.PrintToStdOutOnVerbose("Sequence " + dagSpec.Name + " deployed for " + strconv.Itoa(len(dagSpec.Users)) + " users")
+}
+
+/* a single invocation of the sequence, the rows of the steps come from the activations of its components */
+func runSequenceInstance(dagSpec DagSpec, instanceID int, user string) dagInstance {
+	userAuth := userVsAuthMap[user]
+	seq := instanceID * len(dagSpec.Steps)
+	param, recordedParam := expandParam(dagSpec.Steps[0].Param, seq, user)
+
+	start := time.Now().UnixNano()
+	status, _, _, _, activation := invokeSequenceNative(userAuth, dagSpec.Name, param)
+	end := time.Now().UnixNano()
+
+	instance := dagInstance{ID: instanceID, UserID: user, SubmittedAt: start, EndedAt: end, Steps: len(dagSpec.Steps)}
+	for stepIdx, step := range dagSpec.Steps {
+		resultMap := make(map[string]string)
+		resultMap[commons.DAG_INSTANCE] = strconv.Itoa(instanceID)
+		resultMap[commons.STEP] = step.Name
+		resultMap[commons.STEP_COPY] = "0"
+		resultMap[commons.USER_ID] = user
+		resultMap[commons.FUNCTION_ID] = strconv.Itoa(step.Function)
+		resultMap[commons.SEQ] = strconv.Itoa(seq + stepIdx)
+		if stepIdx == 0 {
+			resultMap[commons.PARAMETER] = recordedParam
+		}
+
+		if stepIdx >= len(activation.Logs) {
+			/* the sequence stopped before this step */
+			instance.Errors++
+			continue
+		}
+
+		stepStatus, execResult, stepActivation := getActivationNative(userAuth, activation.Logs[stepIdx])
+		resultMap[commons.CMD_STATUS] = stepStatus
+		resultMap[commons.CMD_RESULT] = execResult
+		resultMap[commons.SUBMITTED_AT] = strconv.FormatInt(stepActivation.Start*1000000, 10)
+		resultMap[commons.ENDED_AT] = strconv.FormatInt(stepActivation.End*1000000, 10)
+		resultMap[commons.ELAPSED_TIME] = strconv.FormatInt(stepActivation.End-stepActivation.Start, 10)
+		writeDagRow(resultMap)
+
+		if stepStatus != "1" {
+			instance.Errors++
+		}
+	}
+
+	if status != "1" && instance.Errors == 0 {
+		instance.Errors++
+	}
+
+	return instance
+}
+
+func writeDagRow(resultMap map[string]string) {
+	resultMap[commons.ELAPSED_TIME_SINCE_START] = strconv.FormatFloat(time.Since(startRun).Seconds()*1000, 'f', 0, 64)
+	recordSummary(resultMap)
+
+	counterMtx.Lock()
+	defer counterMtx.Unlock()
+
+	if commons.WriteToFile {
+		commons.WriteMapToFile(resultMap, dagOrderArr)
+	} else {
+		commons.WriteMapToOut(resultMap, dagOrderArr)
+	}
+}
+
+/* end to end latency of every instance, along with its percentiles */
+func writeDagInstances(outputFilePath string, instanceArr []dagInstance) {
+	dagFilePath := ""
+	if outputFilePath != "" {
+		dagFilePath = strings.TrimSuffix(outputFilePath, ".csv") + "_dag.csv"
+		commons.OutputFileWriter = commons.CreateOutputFile(dagFilePath)
+	}
+
+	commons.PrintToStdOutOnVerbose("End to end latency of the DAG instances:")
+	commons.PrintHeader(dagInstanceOrderArr, dagFilePath)
+
+	var elapsedArr []float64
+	for _, instance := range instanceArr {
+		elapsed := float64(instance.EndedAt-instance.SubmittedAt) / 1000000
+		status := "1"
+		if instance.Errors > 0 {
+			status = "0"
+		} else {
+			elapsedArr = append(elapsedArr, elapsed)
+		}
+
+		instanceMap := make(map[string]string)
+		instanceMap[commons.DAG_INSTANCE] = strconv.Itoa(instance.ID)
+		instanceMap[commons.USER_ID] = instance.UserID
+		instanceMap[commons.STEPS] = strconv.Itoa(instance.Steps)
+		instanceMap[commons.ERRORS] = strconv.Itoa(instance.Errors)
+		instanceMap[commons.ELAPSED_TIME] = strconv.FormatFloat(elapsed, 'f', 0, 64)
+		instanceMap[commons.SUBMITTED_AT] = strconv.FormatInt(instance.SubmittedAt, 10)
+		instanceMap[commons.ENDED_AT] = strconv.FormatInt(instance.EndedAt, 10)
+		instanceMap[commons.CMD_STATUS] = status
+
+		if commons.WriteToFile {
+			commons.WriteMapToFile(instanceMap, dagInstanceOrderArr)
+		} else {
+			commons.WriteMapToOut(instanceMap, dagInstanceOrderArr)
+		}
+	}
+
+	commons.OutputFileWriter.Close()
+
+	commons.PrintToStdOutOnVerbose("DAG instances: " + strconv.Itoa(len(instanceArr)) + ", failed: " + strconv.Itoa(len(instanceArr)-len(elapsedArr)))
+	commons.PrintToStdOutOnVerbose("  End to end (ms): mean " + formatMs(commons.Mean(elapsedArr)) + ", p50 " + formatMs(commons.Percentile(elapsedArr, 50)) + ", p99 " + formatMs(commons.Percentile(elapsedArr, 99)) + ", max " + formatMs(commons.Percentile(elapsedArr, 100)))
+}
+
+/* invoke the sequence (blocking), the logs of its activation are the activation ids of its components */
+func invokeSequenceNative(userAuth string, sequenceName string, param string) (string, string, int, int, activationRecord) {
+	reqBytes := getParamBody(param)
+	statusCode, respBody, err := doApiRequestBytes("POST", "/api/v1/namespaces/_/actions/"+sequenceName+"?blocking=true", userAuth, reqBytes)
+	var activation activationRecord
+	if err != nil || json.Unmarshal(respBody, &activation) != nil || activation.ActivationID == "" {
+		commons.PrintToStdOutOnDebug("Invocation error of " + sequenceName + " - " + strconv.Itoa(statusCode) + " " + strings.TrimSpace(string(respBody)))
+		return "0", "none, 0, 0, 0", len(reqBytes), len(respBody), activation
+	}
+
+	status := "1"
+	if statusCode != http.StatusOK || !activation.Response.Success {
+		status = "0"
+	}
+
+	return status, activation.getExecResult(), len(reqBytes), len(respBody), activation
+}
+
+/* look up a completed activation, with the status & result like ow-bench.sh */
+func getActivationNative(userAuth string, activationID string) (string, string, activationRecord) {
+	var activation activationRecord
+	for retry := 0; retry < 10; retry++ {
+		statusCode, respBody, err := doApiRequest("GET", "/api/v1/namespaces/_/activations/"+activationID, userAuth, nil)
+		if err == nil && statusCode == http.StatusOK && json.Unmarshal(respBody, &activation) == nil {
+			status := "0"
+			if activation.Response.Success {
+				status = "1"
+			}
+			return status, activation.getExecResult(), activation
+		}
+
+		/* the activation record can take a moment to be stored */
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	return "0", activationID + ", 0, 0, 0", activation
+}
@@ -0,0 +1,275 @@
+package openwhisk
+
+import (
+	"../commons"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var MinIdleGap = 30
+var MaxIdleGap = 900
+var IdleGapStep = 30
+var ProbeMode = "sweep"
+var ProbeRounds = 1
+
+var probeOrderArr = []string{commons.USER_ID, commons.FUNCTION_ID, commons.IDLE_GAP, commons.PROBE_ROUND, commons.CMD_RESULT, commons.IS_COLD_START, commons.ELAPSED_TIME, commons.SUBMITTED_AT, commons.CMD_STATUS}
+var curveOrderArr = []string{commons.IDLE_GAP, commons.PROBES, commons.COLD_STARTS, commons.EVICTION_PROBABILITY}
+
+/*
+ProbeKeepAlive measures how long an idle action container stays warm. Every (user, function) pair in the input file is
+invoked once to warm it up, left idle for a gap (in seconds) and invoked again; the second invocation is recorded as
+cold when OpenWhisk reports an init time for it. In "sweep" mode the gaps from MinIdleGap to MaxIdleGap (in steps of
+IdleGapStep) are spread across all pairs, in "bisect" mode every pair binary searches its own eviction threshold.
+The pairs probe in parallel and the eviction probability vs idle time curve is written at the end.
+*/
+func ProbeKeepAlive(inputFilePath string, outputFilePath string, needCreation bool) {
+	if ProbeMode != "sweep" && ProbeMode != "bisect" {
+		panic(fmt.Errorf("Invalid probe mode - %s", ProbeMode))
+	}
+
+	if MinIdleGap < 0 || MaxIdleGap <= MinIdleGap || IdleGapStep <= 0 {
+		panic(fmt.Errorf("Invalid idle gap range - %d to %d in steps of %d", MinIdleGap, MaxIdleGap, IdleGapStep))
+	}
+
+	batchVsUserFuncMap, uniqueUsersList, usersVsFuncsMap := parseInputFile(inputFilePath)
+	doInitialization(needCreation, uniqueUsersList, usersVsFuncsMap)
+
+	pairArr := getUniquePairs(batchVsUserFuncMap)
+	if len(pairArr) == 0 {
+		panic(fmt.Errorf("No functions to probe in %s", inputFilePath))
+	}
+
+	gapArr := getIdleGaps()
+
+	commons.PrintToStdOutOnVerbose("Probing keep-alive of " + strconv.Itoa(len(pairArr)) + " functions in " + ProbeMode + " mode across " + strconv.Itoa(commons.ConcurrencyFactor) + " co-routines:")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+	if outputFilePath != "" {
+		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
+	}
+
+	commons.PrintHeader(probeOrderArr, outputFilePath)
+
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+	gapVsProbesMap := make(map[int][]int)
+	var thresholdArr []int
+
+	startRun = time.Now()
+	if ProbeMode == "sweep" {
+		pairVsGapsMap := make(map[int][]int)
+		jobIdx := 0
+		for round := 0; round < ProbeRounds; round++ {
+			for _, gap := range gapArr {
+				pairIdx := jobIdx % len(pairArr)
+				pairVsGapsMap[pairIdx] = append(pairVsGapsMap[pairIdx], gap)
+				jobIdx++
+			}
+		}
+
+		for pairIdx, pairGaps := range pairVsGapsMap {
+			wgTime.Add(1)
+
+			go func(userFuncObj UserFuncs, pairGaps []int) {
+				userAuth := userVsAuthMap[userFuncObj.UserID]
+				probeFunction(userAuth, userFuncObj, 0, 0, concChan)
+
+				for round, gap := range pairGaps {
+					time.Sleep(time.Duration(gap) * time.Second)
+					isCold := probeFunction(userAuth, userFuncObj, gap, round+1, concChan)
+
+					counterMtx.Lock()
+					gapVsProbesMap[gap] = append(gapVsProbesMap[gap], boolToInt(isCold))
+					counterMtx.Unlock()
+				}
+
+				wgTime.Done()
+			}(pairArr[pairIdx], pairGaps)
+		}
+	} else {
+		for _, userFuncObj := range pairArr {
+			wgTime.Add(1)
+
+			go func(userFuncObj UserFuncs) {
+				userAuth := userVsAuthMap[userFuncObj.UserID]
+				probeFunction(userAuth, userFuncObj, 0, 0, concChan)
+
+				/* the container is assumed to survive MinIdleGap and to be evicted by MaxIdleGap */
+				lowGap, highGap, round, evicted := MinIdleGap, MaxIdleGap, 0, false
+				for highGap-lowGap > IdleGapStep {
+					round++
+					gap := (lowGap + highGap) / 2
+					time.Sleep(time.Duration(gap) * time.Second)
+
+					if probeFunction(userAuth, userFuncObj, gap, round, concChan) {
+						highGap = gap
+						evicted = true
+					} else {
+						lowGap = gap
+					}
+				}
+
+				counterMtx.Lock()
+				if evicted {
+					thresholdArr = append(thresholdArr, highGap)
+				} else {
+					thresholdArr = append(thresholdArr, -1)
+				}
+				counterMtx.Unlock()
+
+				wgTime.Done()
+			}(userFuncObj)
+		}
+	}
+
+	wgTime.Wait()
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	commons.PrintToStdOutOnVerbose("Probing completed in " + time.Since(startRun).String())
+	commons.OutputFileWriter.Close()
+
+	writeEvictionCurve(outputFilePath, gapArr, gapVsProbesMap, thresholdArr)
+	commons.WriteManifest(outputFilePath, inputFilePath, startRun)
+}
+
+/* invoke the function (blocking) once, record the result & return whether the invocation was a cold start */
+func probeFunction(userAuth string, userFuncObj UserFuncs, gap int, round int, concChan chan int) bool {
+	param, _ := expandParam(userFuncObj.Param, round, userFuncObj.UserID)
+
+	concChan <- 1
+	start := time.Now().UnixNano()
+	status, execResult := invokeFunctionWithAuth(userAuth, strconv.Itoa(userFuncObj.FunctionID), param, false)
+	end := time.Now().UnixNano()
+	<-concChan
+
+	if strings.HasPrefix(execResult, "error") {
+		panic(fmt.Errorf("Error during execution - %s", execResult))
+	}
+
+	isCold := isColdStart(execResult)
+	if round == 0 {
+		/* warm up invocation, nothing to record */
+		return isCold
+	}
+
+	resultMap := make(map[string]string)
+	resultMap[commons.USER_ID] = userFuncObj.UserID
+	resultMap[commons.FUNCTION_ID] = strconv.Itoa(userFuncObj.FunctionID)
+	resultMap[commons.IDLE_GAP] = strconv.Itoa(gap)
+	resultMap[commons.PROBE_ROUND] = strconv.Itoa(round)
+	resultMap[commons.CMD_RESULT] = execResult
+	resultMap[commons.IS_COLD_START] = strconv.FormatBool(isCold)
+	resultMap[commons.ELAPSED_TIME] = strconv.FormatInt((end-start)/1000000, 10)
+	resultMap[commons.SUBMITTED_AT] = strconv.FormatInt(start, 10)
+	resultMap[commons.CMD_STATUS] = status
+
+	counterMtx.Lock()
+	if commons.WriteToFile {
+		commons.WriteMapToFile(resultMap, probeOrderArr)
+	} else {
+		commons.WriteMapToOut(resultMap, probeOrderArr)
+	}
+	counterMtx.Unlock()
+
+	return isCold
+}
+
+/* an activation is a cold start when the annotations carry a non zero init time */
+func isColdStart(execResult string) bool {
+	resultParts := strings.Split(execResult, ", ")
+	if len(resultParts) != 4 {
+		return false
+	}
+
+	initTime, err := strconv.Atoi(strings.TrimSpace(resultParts[2]))
+	return err == nil && initTime > 0
+}
+
+func getUniquePairs(batchVsUserFuncMap map[int][]UserFuncs) []UserFuncs {
+	batchArr := getSortedBatches(batchVsUserFuncMap)
+
+	var exists = struct{}{}
+	seenPairs := make(map[string]struct{})
+	var pairArr []UserFuncs
+
+	for _, batchOfExecution := range batchArr {
+		for _, userFuncObj := range batchVsUserFuncMap[batchOfExecution] {
+			pairKey := userFuncObj.UserID + "/" + strconv.Itoa(userFuncObj.FunctionID)
+			if _, ok := seenPairs[pairKey]; ok {
+				continue
+			}
+
+			seenPairs[pairKey] = exists
+			pairArr = append(pairArr, userFuncObj)
+		}
+	}
+
+	return pairArr
+}
+
+func getIdleGaps() []int {
+	var gapArr []int
+	for gap := MinIdleGap; gap <= MaxIdleGap; gap += IdleGapStep {
+		gapArr = append(gapArr, gap)
+	}
+
+	return gapArr
+}
+
+/*
+writeEvictionCurve writes the eviction probability for each idle gap. In sweep mode it's the fraction of cold probes
+at that gap; in bisect mode it's the fraction of functions whose eviction threshold is at or below that gap.
+*/
+func writeEvictionCurve(outputFilePath string, gapArr []int, gapVsProbesMap map[int][]int, thresholdArr []int) {
+	curveFilePath := ""
+	if outputFilePath != "" {
+		curveFilePath = strings.TrimSuffix(outputFilePath, ".csv") + "_curve.csv"
+		commons.OutputFileWriter = commons.CreateOutputFile(curveFilePath)
+	}
+
+	commons.PrintToStdOutOnVerbose("Eviction probability vs idle time (s):")
+	commons.PrintHeader(curveOrderArr, curveFilePath)
+
+	for _, gap := range gapArr {
+		probes, coldStarts := 0, 0
+		if ProbeMode == "sweep" {
+			for _, isCold := range gapVsProbesMap[gap] {
+				probes++
+				coldStarts += isCold
+			}
+		} else {
+			for _, threshold := range thresholdArr {
+				probes++
+				if threshold != -1 && threshold <= gap {
+					coldStarts++
+				}
+			}
+		}
+
+		if probes == 0 {
+			continue
+		}
+
+		curveMap := make(map[string]string)
+		curveMap[commons.IDLE_GAP] = strconv.Itoa(gap)
+		curveMap[commons.PROBES] = strconv.Itoa(probes)
+		curveMap[commons.COLD_STARTS] = strconv.Itoa(coldStarts)
+		curveMap[commons.EVICTION_PROBABILITY] = strconv.FormatFloat(float64(coldStarts)/float64(probes), 'f', 3, 64)
+
+		if commons.WriteToFile {
+			commons.WriteMapToFile(curveMap, curveOrderArr)
+		} else {
+			commons.WriteMapToOut(curveMap, curveOrderArr)
+		}
+	}
+
+	commons.OutputFileWriter.Close()
+}
+
+func boolToInt(val bool) int {
+	if val {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,295 @@
+package openwhisk
+
+import (
+	"../commons"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var KneeMode = "concurrency"
+var KneeStartLevel = 1.0
+var KneeMaxLevel = 512.0
+var KneeStepFactor = 2.0
+var KneeStepDuration = 30
+var KneeWarmup = 5
+var KneePlateauGain = 0.05
+var KneeLatencyFactor = 3.0
+
+var kneeLevel float64
+var kneeSamples []kneeSample
+
+var capacityOrderArr = []string{commons.STEP, commons.LOAD_LEVEL, commons.COMPLETED, commons.ERRORS, commons.THROUGHPUT, commons.P50_LATENCY, commons.P99_LATENCY, commons.IS_KNEE}
+
+type kneeSample struct {
+	End     time.Time
+	Elapsed float64
+	Failed  bool
+}
+
+/*
+FindKnee steps up the load (no. of concurrent co-routines, or invocations per second in "rate" mode) starting from
+KneeStartLevel, multiplying it by KneeStepFactor every KneeStepDuration seconds. Invocations are drawn round robin
+from the input file. For each step the first KneeWarmup seconds are ignored and the throughput & latency of the rest
+are recorded. The knee is the last step before the throughput stopped growing by KneePlateauGain or the p99 latency
+went beyond KneeLatencyFactor times the p99 of the first step.
+*/
+func FindKnee(inputFilePath string, outputFilePath string, needCreation bool) {
+	if KneeMode != "concurrency" && KneeMode != "rate" {
+		panic(fmt.Errorf("Invalid knee mode - %s", KneeMode))
+	}
+
+	if KneeStartLevel <= 0 || KneeStepFactor <= 1 || KneeWarmup >= KneeStepDuration {
+		panic(fmt.Errorf("Invalid knee steps - start %.2f, factor %.2f, warm up %ds, duration %ds", KneeStartLevel, KneeStepFactor, KneeWarmup, KneeStepDuration))
+	}
+
+	runStartedAt := time.Now()
+	batchVsUserFuncMap, uniqueUsersList, usersVsFuncsMap := parseInputFile(inputFilePath)
+	doInitialization(needCreation, uniqueUsersList, usersVsFuncsMap)
+
+	batchArr := getSortedBatches(batchVsUserFuncMap)
+
+	var invocationArr []map[string]string
+	for _, batchOfExecution := range batchArr {
+		for _, userFuncObj := range batchVsUserFuncMap[batchOfExecution] {
+			for i := 1; i <= userFuncObj.NoOfTimesToExecute; i++ {
+				cmdMap := make(map[string]string)
+				cmdMap[commons.BATCH] = strconv.Itoa(batchOfExecution)
+				cmdMap[commons.USER_ID] = userFuncObj.UserID
+				cmdMap[commons.USER_AUTH] = userVsAuthMap[userFuncObj.UserID]
+				cmdMap[commons.FUNCTION_ID] = strconv.Itoa(userFuncObj.FunctionID)
+				cmdMap[commons.PARAMETER] = userFuncObj.Param
+				invocationArr = append(invocationArr, cmdMap)
+			}
+		}
+	}
+
+	if len(invocationArr) == 0 {
+		panic(fmt.Errorf("No invocations found in %s", inputFilePath))
+	}
+
+	commons.PrintToStdOutOnVerbose("Searching the throughput knee in " + KneeMode + " mode starting at " + strconv.FormatFloat(KneeStartLevel, 'f', 2, 64) + ":")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+	if outputFilePath != "" {
+		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
+	}
+
+	commons.PrintHeader(orderArr, outputFilePath)
+
+	invocationChan := make(chan map[string]string)
+	stopChan := make(chan struct{})
+	dispatcherDone := make(chan struct{})
+
+	/* the rate of the first step is set before the dispatcher reads it */
+	counterMtx.Lock()
+	kneeLevel = KneeStartLevel
+	counterMtx.Unlock()
+
+	startRun = time.Now()
+	go dispatchKneeInvocations(invocationArr, invocationChan, stopChan, dispatcherDone)
+
+	var capacityArr []map[string]string
+	kneeIdx := -1
+	workerCount := 0
+	level := KneeStartLevel
+
+	for step := 1; level <= KneeMaxLevel; step++ {
+		if KneeMode == "concurrency" {
+			for ; workerCount < int(level); workerCount++ {
+				wgTime.Add(1)
+				go kneeWorker(invocationChan, stopChan)
+			}
+			/* the workers read it for their rows, under the lock */
+			counterMtx.Lock()
+			commons.ConcurrencyFactor = workerCount
+			counterMtx.Unlock()
+		} else {
+			counterMtx.Lock()
+			kneeLevel = level
+			counterMtx.Unlock()
+		}
+
+		stepStart := time.Now()
+		time.Sleep(time.Duration(KneeStepDuration) * time.Second)
+		capacityMap := summarizeKneeStep(stepStart.Add(time.Duration(KneeWarmup)*time.Second), time.Now())
+		capacityMap[commons.STEP] = strconv.Itoa(step)
+		capacityMap[commons.LOAD_LEVEL] = strconv.FormatFloat(level, 'f', 2, 64)
+		capacityMap[commons.IS_KNEE] = "false"
+		capacityArr = append(capacityArr, capacityMap)
+
+		commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+		commons.PrintToStdOutOnVerbose("Step #" + strconv.Itoa(step) + " at " + capacityMap[commons.LOAD_LEVEL] + ": throughput " + capacityMap[commons.THROUGHPUT] + "/s, p99 " + capacityMap[commons.P99_LATENCY] + " ms")
+		commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+		if isKneePassed(capacityArr) {
+			kneeIdx = len(capacityArr) - 2
+			break
+		}
+
+		nextLevel := level * KneeStepFactor
+		if KneeMode == "concurrency" && int(nextLevel) == int(level) {
+			nextLevel = level + 1
+		}
+		level = nextLevel
+	}
+
+	close(stopChan)
+	<-dispatcherDone
+	wgTime.Wait()
+	commons.OutputFileWriter.Close()
+
+	if kneeIdx >= 0 {
+		capacityArr[kneeIdx][commons.IS_KNEE] = "true"
+		commons.PrintToStdOutOnVerbose("Knee found at " + capacityArr[kneeIdx][commons.LOAD_LEVEL] + " with throughput " + capacityArr[kneeIdx][commons.THROUGHPUT] + "/s")
+	} else {
+		commons.PrintToStdOutOnVerbose("No knee found up to " + strconv.FormatFloat(KneeMaxLevel, 'f', 2, 64))
+	}
+
+	writeCapacityCurve(outputFilePath, capacityArr)
+	commons.WriteManifest(outputFilePath, inputFilePath, runStartedAt)
+}
+
+/* keep feeding the workers (concurrency mode) or fire invocations at the current rate (rate mode) till stopped */
+func dispatchKneeInvocations(invocationArr []map[string]string, invocationChan chan map[string]string, stopChan chan struct{}, dispatcherDone chan struct{}) {
+	defer close(dispatcherDone)
+
+	nextDispatch := time.Now()
+	for seq := 0; ; seq++ {
+		cmdMap := commons.CopyMap(invocationArr[seq%len(invocationArr)])
+		cmdMap[commons.SEQ] = strconv.Itoa(seq)
+
+		if KneeMode == "concurrency" {
+			select {
+			case <-stopChan:
+				return
+			case invocationChan <- cmdMap:
+			}
+			continue
+		}
+
+		counterMtx.Lock()
+		rate := kneeLevel
+		counterMtx.Unlock()
+
+		if rate <= 0 {
+			panic(fmt.Errorf("Invalid knee rate - %.2f", rate))
+		}
+		interval := time.Duration(float64(time.Second) / rate)
+
+		nextDispatch = nextDispatch.Add(interval)
+		select {
+		case <-stopChan:
+			return
+		case <-time.After(time.Until(nextDispatch)):
+		}
+
+		wgTime.Add(1)
+		go func(cmdMap map[string]string) {
+			invokeAndSample(cmdMap)
+			wgTime.Done()
+		}(cmdMap)
+	}
+}
+
+func kneeWorker(invocationChan chan map[string]string, stopChan chan struct{}) {
+	for {
+		select {
+		case <-stopChan:
+			wgTime.Done()
+			return
+		case cmdMap := <-invocationChan:
+			invokeAndSample(cmdMap)
+		}
+	}
+}
+
+func invokeAndSample(cmdMap map[string]string) {
+	resultMap := invokeAndProcess(cmdMap)
+	endedAt, _ := strconv.ParseInt(resultMap[commons.ENDED_AT], 10, 64)
+	elapsed, _ := strconv.ParseFloat(resultMap[commons.ELAPSED_TIME], 64)
+
+	counterMtx.Lock()
+	kneeSamples = append(kneeSamples, kneeSample{
+		End:     time.Unix(0, endedAt),
+		Elapsed: elapsed,
+		Failed:  resultMap[commons.CMD_STATUS] != "1" || strings.HasPrefix(resultMap[commons.CMD_RESULT], "error"),
+	})
+	counterMtx.Unlock()
+}
+
+/* throughput & latency of the invocations completed in the measurement window of a step */
+func summarizeKneeStep(windowStart time.Time, windowEnd time.Time) map[string]string {
+	var elapsedArr []float64
+	errorCount := 0
+
+	counterMtx.Lock()
+	for _, sample := range kneeSamples {
+		if sample.End.Before(windowStart) || sample.End.After(windowEnd) {
+			continue
+		}
+
+		if sample.Failed {
+			errorCount++
+		} else {
+			elapsedArr = append(elapsedArr, sample.Elapsed)
+		}
+	}
+	counterMtx.Unlock()
+
+	capacityMap := make(map[string]string)
+	capacityMap[commons.COMPLETED] = strconv.Itoa(len(elapsedArr))
+	capacityMap[commons.ERRORS] = strconv.Itoa(errorCount)
+	capacityMap[commons.THROUGHPUT] = strconv.FormatFloat(float64(len(elapsedArr))/windowEnd.Sub(windowStart).Seconds(), 'f', 2, 64)
+	capacityMap[commons.P50_LATENCY] = strconv.FormatFloat(commons.Percentile(elapsedArr, 50), 'f', 0, 64)
+	capacityMap[commons.P99_LATENCY] = strconv.FormatFloat(commons.Percentile(elapsedArr, 99), 'f', 0, 64)
+	return capacityMap
+}
+
+/* the last step is past the knee when its throughput has plateaued or its p99 latency has blown up */
+func isKneePassed(capacityArr []map[string]string) bool {
+	if len(capacityArr) < 2 {
+		return false
+	}
+
+	lastStep := capacityArr[len(capacityArr)-1]
+	prevStep := capacityArr[len(capacityArr)-2]
+
+	lastThroughput, _ := strconv.ParseFloat(lastStep[commons.THROUGHPUT], 64)
+	prevThroughput, _ := strconv.ParseFloat(prevStep[commons.THROUGHPUT], 64)
+	if lastThroughput < prevThroughput*(1+KneePlateauGain) {
+		commons.PrintToStdOutOnVerbose("Throughput plateaued: " + prevStep[commons.THROUGHPUT] + "/s -> " + lastStep[commons.THROUGHPUT] + "/s")
+		return true
+	}
+
+	baseP99, _ := strconv.ParseFloat(capacityArr[0][commons.P99_LATENCY], 64)
+	lastP99, _ := strconv.ParseFloat(lastStep[commons.P99_LATENCY], 64)
+	if baseP99 > 0 && lastP99 > baseP99*KneeLatencyFactor {
+		commons.PrintToStdOutOnVerbose("Latency blew up: p99 " + capacityArr[0][commons.P99_LATENCY] + " ms -> " + lastStep[commons.P99_LATENCY] + " ms")
+		return true
+	}
+
+	return false
+}
+
+func writeCapacityCurve(outputFilePath string, capacityArr []map[string]string) {
+	curveFilePath := ""
+	if outputFilePath != "" {
+		curveFilePath = strings.TrimSuffix(outputFilePath, ".csv") + "_capacity.csv"
+		commons.OutputFileWriter = commons.CreateOutputFile(curveFilePath)
+	}
+
+	commons.PrintToStdOutOnVerbose("Capacity curve:")
+	commons.PrintHeader(capacityOrderArr, curveFilePath)
+
+	for _, capacityMap := range capacityArr {
+		if commons.WriteToFile {
+			commons.WriteMapToFile(capacityMap, capacityOrderArr)
+		} else {
+			commons.WriteMapToOut(capacityMap, capacityOrderArr)
+		}
+	}
+
+	commons.OutputFileWriter.Close()
+}
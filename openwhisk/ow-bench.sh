@@ -211,6 +211,29 @@ function createFunction
     echo -e "{\"status\":\"$status\", \"output\":\"$output\"}"
 }
 
+function removeUser
+{
+    user=$1
+    if [ -z "$user" ]; then
+        echo "Error: Too Few Parameters to removeUser"
+        return
+    fi
+
+	output=$(bash -c "wskadmin user delete $user" 2>&1)
+	if [ $? -eq 0 ]; then
+	    status=1
+	    output=$user
+	elif [[ $output == *"not exist"* ]]; then
+	    status=1
+	    output="$user does not exist"
+	else
+	    status=0
+	fi
+
+    output="${output//\"/\'}"
+	echo -e "{\"status\":\"$status\", \"output\":\"$output\"}"
+}
+
 function randomFunction
 {
 	createFunction guest $RANDOM 
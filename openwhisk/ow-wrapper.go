@@ -2,22 +2,21 @@ package openwhisk
 
 import (
 	"../commons"
-	"bufio"
 	"bytes"
 	"fmt"
 	"log"
-	"os"
 	"os/exec"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var userVsAuthMap = make(map[string]string)
 var activationList []map[string]string
-var cmdChan = make(chan map[string]string)
+var cmdChan = make(chan invocationRecord)
 
 var wgTime = sync.WaitGroup{}
 var counterMtx sync.Mutex
@@ -30,44 +29,39 @@ var execCount = 0
 var orderArr = []string{commons.BATCH, commons.USER_ID, commons.FUNCTION_ID, commons.SEQ, commons.CMD_RESULT, commons.ELAPSED_TIME, commons.ELAPSED_TIME_SINCE_START, commons.SUBMITTED_AT, commons.ENDED_AT, commons.EXEC_RATE, commons.CMD_STATUS, commons.CONCURRENCY_FACTOR, commons.PARAMETER}
 
 func ExecCmdsFromFile(inputFilePath string, outputFilePath string, needCreation bool) {
-	commons.PrintToStdOutOnVerbose("Parsing File: " + inputFilePath)
+	runStartedAt := time.Now()
+	usersVsFuncsMap := runWorkloadFile(inputFilePath, outputFilePath, needCreation)
+	commons.WriteManifest(outputFilePath, inputFilePath, runStartedAt)
 
-	fread, err := os.Open(inputFilePath)
-	if err != nil {
-		panic(fmt.Errorf("File error - %s", err))
-	}
-
-	scanner := bufio.NewScanner(fread)
+	doTeardown(usersVsFuncsMap, TeardownPolicy)
+}
 
-	batchVsUserFuncMap := make(map[int][]UserFuncs)
+/* run the workload file, leaving the manifest & the teardown to the caller, returns the users & functions it ran */
+func runWorkloadFile(inputFilePath string, outputFilePath string, needCreation bool) map[string]map[int]struct{} {
+	var batchVsUserFuncMap map[int][]UserFuncs
+	var uniqueUsersList map[string]struct{}
+	var usersVsFuncsMap map[string]map[int]struct{}
+	if SessionMode && IsAsync {
+		panic(fmt.Errorf("Sessions wait for the result of every invocation, they can't be asynchronous"))
+	}
 
-	{
-		var exists = struct{}{}
-		uniqueUsersList := make(map[string]struct{})
-		usersVsFuncsMap := make(map[string]map[int]struct{})
-
-		for scanner.Scan() {
-			//fmt.Printf("%+v\n", scanner.Text())
-			lineParts := strings.Split(scanner.Text(), ",")
-			userFuncObj := createUserFuncsObj(lineParts)
-			userFuncArr := batchVsUserFuncMap[userFuncObj.Time]
-			userFuncArr = append(userFuncArr, userFuncObj)
-			batchVsUserFuncMap[userFuncObj.Time] = userFuncArr
-			uniqueUsersList[userFuncObj.UserID] = exists
-
-			if needCreation {
-				uniqueFuncList, ok := usersVsFuncsMap[userFuncObj.UserID]
-				if !ok {
-					uniqueFuncList = make(map[int]struct{})
-				}
-
-				uniqueFuncList[userFuncObj.FunctionID] = exists
-				usersVsFuncsMap[userFuncObj.UserID] = uniqueFuncList
-			}
+	isStreamed := false
+	if StreamWorkload && SessionMode {
+		commons.PrintToStdOutOnVerbose("Sessions need the invocations of a batch up front, " + inputFilePath + " is read as a whole")
+	} else if StreamWorkload {
+		if inputFilePath == STDIN_WORKLOAD && (needCreation || commons.RunForever) {
+			panic(fmt.Errorf("A workload streamed from stdin can't be created or run forever"))
 		}
 
-		doInitialization(needCreation, uniqueUsersList, usersVsFuncsMap)
+		uniqueUsersList, usersVsFuncsMap, isStreamed = scanWorkload(inputFilePath, needCreation || isWarmupEnabled())
+		if !isStreamed {
+			commons.PrintToStdOutOnVerbose(inputFilePath + " isn't ordered by batch, it's read as a whole")
+		}
 	}
+	if !isStreamed {
+		batchVsUserFuncMap, uniqueUsersList, usersVsFuncsMap = parseInputFile(inputFilePath)
+	}
+	doInitialization(needCreation, uniqueUsersList, usersVsFuncsMap)
 
 	commons.PrintToStdOutOnVerbose("Starting function invocations across " + strconv.Itoa(commons.ConcurrencyFactor) + " co-routines:")
 	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
@@ -76,63 +70,43 @@ func ExecCmdsFromFile(inputFilePath string, outputFilePath string, needCreation
 		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
 	}
 
+	if isWarmupEnabled() {
+		addPhaseColumn()
+	}
+
+	if SessionMode {
+		addThinkTimeColumn()
+	}
+
 	commons.PrintHeader(orderArr, outputFilePath)
 
-	batchArr := make([]int, 0, len(batchVsUserFuncMap))
-	for batchOfExecution := range batchVsUserFuncMap {
-		batchArr = append(batchArr, batchOfExecution)
+	if isStreamed {
+		runWarmup(getFuncPairs(usersVsFuncsMap))
+	} else {
+		runWarmup(getUniquePairs(batchVsUserFuncMap))
 	}
-	sort.Ints(batchArr)
 
+	/* a channel per run, closing it stops the co-routines once the run is over */
+	cmdChan = make(chan invocationRecord)
 	for i := 0; i < commons.ConcurrencyFactor; i++ {
 		go invokeFunction()
 	}
 
+	resultStopChan := make(chan struct{})
 	if IsAsync {
-		go getResult()
+		go getResult(resultStopChan)
 	}
 
 	totalExecCount := 0
 	startRun = time.Now()
+	timelineStopChan := startTimeline()
 	for {
-		for _, batchOfExecution := range batchArr {
-			batchExecCount := 0
-			startBatch := time.Now()
-			for _, userFuncObj := range batchVsUserFuncMap[batchOfExecution] {
-				userAuth := userVsAuthMap[userFuncObj.UserID]
-				for i := 1; i <= userFuncObj.NoOfTimesToExecute; i++ {
-					cmdMap := make(map[string]string)
-					cmdMap[commons.BATCH] = strconv.Itoa(batchOfExecution)
-					cmdMap[commons.USER_ID] = userFuncObj.UserID
-					cmdMap[commons.USER_AUTH] = userAuth
-					cmdMap[commons.FUNCTION_ID] = strconv.Itoa(userFuncObj.FunctionID)
-					cmdMap[commons.PARAMETER] = userFuncObj.Param
-					cmdMap[commons.SEQ] = strconv.Itoa(totalExecCount)
-					wgTime.Add(1)
-					batchExecCount++
-					totalExecCount++
-
-					if commons.RateLimit != 0.0 && currExecRate > commons.RateLimit {
-						//sleepTime := int((currExecRate/(rateLimit*5))*1000)
-						//fmt.Println("Exec Count: " + strconv.Itoa(execCount) + ", Seq: " + strconv.Itoa(totalExecCount) + ", Exec Rate: " + strconv.FormatFloat(currExecRate, 'f', 2, 64) + ", Sleep Time: " + strconv.Itoa(sleepTime))
-						//time.Sleep(time.Duration(sleepTime) * time.Millisecond)
-						time.Sleep(500 * time.Millisecond)
-					}
-
-					cmdChan <- cmdMap
-				}
-			}
-
-			wgTime.Wait()
-
-			batchElapse := time.Since(startBatch)
-			commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
-			commons.PrintToStdOutOnVerbose("Batch #" + strconv.Itoa(batchOfExecution) + " completed " + strconv.Itoa(batchExecCount) + " executions in " + strconv.FormatFloat(batchElapse.Seconds()*1000, 'f', 0, 64) + "  ms")
-			commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
-			if commons.BatchDelay > 0 {
-				time.Sleep(time.Duration(commons.BatchDelay) * time.Millisecond)
-			}
-
+		if isStreamed {
+			totalExecCount = streamBatches(inputFilePath, totalExecCount, usersVsFuncsMap)
+		} else if SessionMode {
+			totalExecCount = dispatchSessions(batchVsUserFuncMap, totalExecCount)
+		} else {
+			totalExecCount = dispatchBatches(batchVsUserFuncMap, totalExecCount)
 		}
 
 		if !commons.RunForever {
@@ -146,8 +120,105 @@ func ExecCmdsFromFile(inputFilePath string, outputFilePath string, needCreation
 	commons.PrintToStdOutOnVerbose("Total time: " + strconv.FormatFloat(elapsedTimeInMs, 'f', 0, 64) + " ms")
 	commons.PrintToStdOutOnVerbose("Total executions: " + strconv.Itoa(totalExecCount))
 	commons.PrintToStdOutOnVerbose("Execution Rate: " + strconv.FormatFloat(float64(totalExecCount)/(elapsedTimeInMs/1000), 'f', 2, 64))
+	printSummary()
 
+	close(cmdChan)
+	close(resultStopChan)
 	commons.OutputFileWriter.Close()
+	stopTimeline(timelineStopChan, outputFilePath)
+
+	return usersVsFuncsMap
+}
+
+/* dispatch the batches of a parsed workload in order, returns the total no. of executions so far */
+func dispatchBatches(batchVsUserFuncMap map[int][]UserFuncs, totalExecCount int) int {
+	for _, batchOfExecution := range getSortedBatches(batchVsUserFuncMap) {
+		batchExecCount := 0
+		startBatch := time.Now()
+		for _, userFuncObj := range batchVsUserFuncMap[batchOfExecution] {
+			for i := 1; i <= userFuncObj.NoOfTimesToExecute; i++ {
+				dispatchInvocation(createInvocationRecord(userFuncObj, totalExecCount))
+				batchExecCount++
+				totalExecCount++
+			}
+		}
+
+		completeBatch(batchOfExecution, batchExecCount, startBatch)
+	}
+
+	return totalExecCount
+}
+
+func createInvocationRecord(userFuncObj UserFuncs, seq int) invocationRecord {
+	return invocationRecord{
+		Batch:      userFuncObj.Time,
+		Seq:        seq,
+		Phase:      MEASURE_PHASE,
+		UserID:     userFuncObj.UserID,
+		UserAuth:   userVsAuthMap[userFuncObj.UserID],
+		FunctionID: userFuncObj.FunctionID,
+		Param:      userFuncObj.Param,
+	}
+}
+
+/* hand the invocation to a co-routine, held back while the execution rate is over the rate limit */
+func dispatchInvocation(record invocationRecord) {
+	wgTime.Add(1)
+	holdForRateLimit()
+	cmdChan <- record
+}
+
+/* hold back the next invocation while the execution rate is over the rate limit */
+func holdForRateLimit() {
+	counterMtx.Lock()
+	isOverLimit := commons.RateLimit != 0.0 && currExecRate > commons.RateLimit
+	counterMtx.Unlock()
+
+	if isOverLimit {
+		//sleepTime := int((currExecRate/(rateLimit*5))*1000)
+		//fmt.Println("Exec Count: " + strconv.Itoa(execCount) + ", Seq: " + strconv.Itoa(totalExecCount) + ", Exec Rate: " + strconv.FormatFloat(currExecRate, 'f', 2, 64) + ", Sleep Time: " + strconv.Itoa(sleepTime))
+		//time.Sleep(time.Duration(sleepTime) * time.Millisecond)
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+/* wait for the invocations of the batch to complete, then for the delay between batches */
+func completeBatch(batchOfExecution int, batchExecCount int, startBatch time.Time) {
+	wgTime.Wait()
+
+	batchElapse := time.Since(startBatch)
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	commons.PrintToStdOutOnVerbose("Batch #" + strconv.Itoa(batchOfExecution) + " completed " + strconv.Itoa(batchExecCount) + " executions in " + strconv.FormatFloat(batchElapse.Seconds()*1000, 'f', 0, 64) + "  ms")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	if commons.BatchDelay > 0 {
+		time.Sleep(time.Duration(commons.BatchDelay) * time.Millisecond)
+	}
+}
+
+/* parse the input file into batches of user functions along with the unique users & their functions */
+func parseInputFile(inputFilePath string) (map[int][]UserFuncs, map[string]struct{}, map[string]map[int]struct{}) {
+	commons.PrintToStdOutOnVerbose("Parsing File: " + inputFilePath)
+
+	batchVsUserFuncMap := make(map[int][]UserFuncs)
+	uniqueUsersList := make(map[string]struct{})
+	usersVsFuncsMap := make(map[string]map[int]struct{})
+
+	readWorkload(inputFilePath, func(userFuncObj UserFuncs, lineNo int) {
+		batchVsUserFuncMap[userFuncObj.Time] = append(batchVsUserFuncMap[userFuncObj.Time], userFuncObj)
+		addUserFunc(uniqueUsersList, usersVsFuncsMap, userFuncObj)
+	})
+
+	return batchVsUserFuncMap, uniqueUsersList, usersVsFuncsMap
+}
+
+func getSortedBatches(batchVsUserFuncMap map[int][]UserFuncs) []int {
+	batchArr := make([]int, 0, len(batchVsUserFuncMap))
+	for batchOfExecution := range batchVsUserFuncMap {
+		batchArr = append(batchArr, batchOfExecution)
+	}
+	sort.Ints(batchArr)
+
+	return batchArr
 }
 
 /* execute single openwhisk cli command with argsArr arguments */
@@ -170,18 +241,31 @@ func ExecCmd(argsArr []string) string {
 	return strings.Trim(string(cmdOut), " \n")
 }
 
+/* reset the counters & results of the previous run, so that several runs can go in a single process */
+func resetRunState() {
+	counterMtx.Lock()
+	execCount = 0
+	currExecRate = 0
+	activationList = nil
+	measuredSummary = runSummary{}
+	counterMtx.Unlock()
+}
+
 func processResult(resultMap map[string]string) {
 	delete(resultMap, commons.USER_AUTH)
 	elapsedTimeSinceStart := time.Since(startRun).Seconds() * 1000
 	resultMap[commons.ELAPSED_TIME_SINCE_START] = strconv.FormatFloat(elapsedTimeSinceStart, 'f', 0, 64)
-	resultMap[commons.CONCURRENCY_FACTOR] = strconv.Itoa(commons.ConcurrencyFactor)
 
 	counterMtx.Lock()
+	resultMap[commons.CONCURRENCY_FACTOR] = strconv.Itoa(commons.ConcurrencyFactor)
 	execCount += 1
 	currExecRate = float64(execCount) / (elapsedTimeSinceStart / 1000)
 	resultMap[commons.EXEC_RATE] = strconv.FormatFloat(currExecRate, 'f', 2, 64)
 	counterMtx.Unlock()
 
+	recordSummary(resultMap)
+	recordTimeline(resultMap)
+
 	if commons.WriteToFile {
 		commons.WriteMapToFile(resultMap, orderArr)
 	} else {
@@ -205,110 +289,133 @@ func doExecAndParse(paramArr []string, retryCount int) string {
 
 func doInitialization(needCreation bool, uniqueUsersList map[string]struct{}, usersVsFuncsMap map[string]map[int]struct{}) {
 	commons.PrintToStdOutOnVerbose("Creation Needed: " + strconv.FormatBool(needCreation))
+	loadResultChecks()
+	initInvokeMode()
 
+	if needCreation {
+		createUsers(uniqueUsersList)
+		if ActionSpecFile != "" {
+			deployFunctions(usersVsFuncsMap, parseActionSpecs(ActionSpecFile))
+		} else if InvokeMode == INVOKE_WEB {
+			/* ow-bench.sh doesn't export the functions it creates */
+			deployFunctions(usersVsFuncsMap, nil)
+		} else {
+			createFunctions(usersVsFuncsMap)
+		}
+	} else {
+		loadUserAuths(uniqueUsersList)
+	}
+}
+
+func createUsers(uniqueUsersList map[string]struct{}) {
 	var concChan = make(chan int, commons.ConcurrencyFactor)
+	readAuthCache()
 
-	if needCreation {
-		startTime := time.Now()
-		for user := range uniqueUsersList {
+	startTime := time.Now()
+	for user := range uniqueUsersList {
+		concChan <- 1
+		wgTime.Add(1)
+
+		go func(user string) {
+			parsedJson := doExecAndParse([]string{"createUser", user}, 10)
+			userAuth := strings.Split(parsedJson, " ")[1]
+			cacheUserAuth(user, userAuth)
+
+			counterMtx.Lock()
+			userVsAuthMap[user] = userAuth
+			counterMtx.Unlock()
+
+			wgTime.Done()
+			<-concChan
+		}(user)
+	}
+
+	wgTime.Wait()
+	saveAuthCache()
+	commons.PrintToStdOutOnVerbose(strconv.Itoa(len(uniqueUsersList)) + " users created. Time taken = " + time.Since(startTime).String())
+}
+
+func createFunctions(usersVsFuncsMap map[string]map[int]struct{}) {
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+
+	totalFuncsCreated := 0
+	startTime := time.Now()
+	for user, funcList := range usersVsFuncsMap {
+		//userAuth := userVsAuthMap[user]
+		for funcName := range funcList {
 			concChan <- 1
 			wgTime.Add(1)
 
-			go func(user string) {
-				parsedJson := doExecAndParse([]string{"createUser", user}, 10)
-				userAuth := strings.Split(parsedJson, " ")[1]
-
-				counterMtx.Lock()
-				userVsAuthMap[user] = userAuth
-				counterMtx.Unlock()
+			go func(user string, funcName int) {
+				doExecAndParse([]string{"createFunction", user, strconv.Itoa(funcName), "functions/trial.js"}, 5)
 
 				wgTime.Done()
 				<-concChan
-			}(user)
+			}(user, funcName)
 		}
 
-		wgTime.Wait()
-		commons.PrintToStdOutOnVerbose(strconv.Itoa(len(uniqueUsersList)) + " users created. Time taken = " + time.Since(startTime).String())
-
-		totalFuncsCreated := 0
-		startTime = time.Now()
-		for user, funcList := range usersVsFuncsMap {
-			//userAuth := userVsAuthMap[user]
-			for funcName := range funcList {
-				concChan <- 1
-				wgTime.Add(1)
+		totalFuncsCreated += len(funcList)
+		commons.PrintToStdOutOnDebug(strconv.Itoa(len(funcList)) + " functions created for " + user)
+	}
 
-				go func(user string, funcName int) {
-					doExecAndParse([]string{"createFunction", user, strconv.Itoa(funcName), "functions/trial.js"}, 5)
+	wgTime.Wait()
+	commons.PrintToStdOutOnVerbose(strconv.Itoa(totalFuncsCreated) + " functions created. Time taken = " + time.Since(startTime).String())
+}
 
-					wgTime.Done()
-					<-concChan
-				}(user, funcName)
-			}
+/* load the auth details of the users not loaded (or created) already, from the auth cache if possible */
+func loadUserAuths(uniqueUsersList map[string]struct{}) {
+	var concChan = make(chan int, commons.ConcurrencyFactor)
 
-			totalFuncsCreated += len(funcList)
-			commons.PrintToStdOutOnDebug(strconv.Itoa(len(funcList)) + " functions created for " + user)
+	startTime := time.Now()
+	loadCachedAuths(uniqueUsersList)
+	for user := range uniqueUsersList {
+		if _, ok := userVsAuthMap[user]; ok {
+			continue
 		}
 
-		wgTime.Wait()
-		commons.PrintToStdOutOnVerbose(strconv.Itoa(totalFuncsCreated) + " functions created. Time taken = " + time.Since(startTime).String())
-	} else {
-		startTime := time.Now()
-		for user := range uniqueUsersList {
-			concChan <- 1
-			wgTime.Add(1)
+		concChan <- 1
+		wgTime.Add(1)
 
-			go func(user string) {
-				userAuth := doExecAndParse([]string{"getUserAuth", user}, 10)
+		go func(user string) {
+			userAuth := doExecAndParse([]string{"getUserAuth", user}, 10)
+			cacheUserAuth(user, userAuth)
 
-				counterMtx.Lock()
-				userVsAuthMap[user] = userAuth
-				counterMtx.Unlock()
+			counterMtx.Lock()
+			userVsAuthMap[user] = userAuth
+			counterMtx.Unlock()
 
-				wgTime.Done()
-				<-concChan
-			}(user)
-		}
-
-		wgTime.Wait()
-		commons.PrintToStdOutOnVerbose(strconv.Itoa(len(uniqueUsersList)) + " users are loaded with their auth details. Time taken = " + time.Since(startTime).String())
+			wgTime.Done()
+			<-concChan
+		}(user)
 	}
+
+	wgTime.Wait()
+	saveAuthCache()
+	commons.PrintToStdOutOnVerbose(strconv.Itoa(len(uniqueUsersList)) + " users are loaded with their auth details. Time taken = " + time.Since(startTime).String())
 }
 
 func invokeFunction() {
-	for cmdMap := range cmdChan {
+	for record := range cmdChan {
+		cmdMap := record.getCmdMap()
 		userAuth := cmdMap[commons.USER_AUTH]
 		functionID := cmdMap[commons.FUNCTION_ID]
-		param := cmdMap[commons.PARAMETER]
+		param, recordedParam := expandParam(cmdMap[commons.PARAMETER], record.Seq, record.UserID)
+		cmdMap[commons.PARAMETER] = recordedParam
 
 		start := time.Now().UnixNano()
-
-		cmd := "invokeFunctionWithAuth"
-		if IsAsync {
-			cmd = "invokeFunctionWithAuthAsync"
-		}
-
-		var paramArr []string
-		if IsAsync {
-			paramArr = []string{cmd, userAuth, functionID}
-		} else {
-			paramArr = []string{cmd, "false", userAuth, functionID}
-		}
-
-		if param != "" {
-			paramArr = append(paramArr, "--param", param)
-		}
-
-		jsonStr := ExecCmd(paramArr)
-		status, execResult := commons.ParseJsonResponse(jsonStr)
+		status, execResult, result, timing := invokeInMode(userAuth, functionID, param, IsAsync)
 
 		end := time.Now().UnixNano()
 		elapsed := (end - start) / 1000000 /* nano to milli */
+		execResult = resolveWebResult(userAuth, execResult)
+		checkStatus := checkResult(userAuth, functionID, param, status, execResult, result, IsAsync)
 
 		resultMap := commons.CopyMap(cmdMap)
 		resultMap[commons.CMD_STATUS] = status
 		resultMap[commons.CMD_RESULT] = execResult
+		resultMap[commons.CHECK_STATUS] = checkStatus
 		resultMap[commons.SUBMITTED_AT] = strconv.FormatInt(start, 10)
+		timing.addTo(resultMap)
 
 		if IsAsync {
 			activationList = append(activationList, resultMap)
@@ -325,7 +432,65 @@ func invokeFunction() {
 	}
 }
 
-func getResult() {
+/* invoke the function through ow-bench.sh, returns the status with the activation result (or its id when async) */
+func invokeFunctionWithAuth(userAuth string, functionID string, param string, isAsync bool) (string, string) {
+	cmd := "invokeFunctionWithAuth"
+	if isAsync {
+		cmd = "invokeFunctionWithAuthAsync"
+	}
+
+	var paramArr []string
+	if isAsync {
+		paramArr = []string{cmd, userAuth, functionID}
+	} else {
+		paramArr = []string{cmd, "false", userAuth, functionID}
+	}
+
+	paramArr = append(paramArr, getParamArgs(param)...)
+
+	/* a command too long for ow-bench.sh fails the invocation rather than the run, async ones are checked at parse */
+	if length := len(strings.Join(paramArr, " ")); !isAsync && length > maxArgLength {
+		commons.PrintToStdOutOnDebug("Invocation error of " + functionID + " - a command of " + strconv.Itoa(length) + " bytes")
+		return "0", "none, 0, 0, 0"
+	}
+
+	/* async invocations stay in flight until getResult finds their activation */
+	atomic.AddInt32(&inFlightCount, 1)
+	if !isAsync {
+		defer atomic.AddInt32(&inFlightCount, -1)
+	}
+
+	jsonStr := ExecCmd(paramArr)
+	return commons.ParseJsonResponse(jsonStr)
+}
+
+/* invoke the function (blocking), write out the result & return it */
+func invokeAndProcess(cmdMap map[string]string) map[string]string {
+	seq, _ := strconv.Atoi(cmdMap[commons.SEQ])
+	param, recordedParam := expandParam(cmdMap[commons.PARAMETER], seq, cmdMap[commons.USER_ID])
+
+	start := time.Now().UnixNano()
+	status, execResult, result, timing := invokeInMode(cmdMap[commons.USER_AUTH], cmdMap[commons.FUNCTION_ID], param, false)
+	end := time.Now().UnixNano()
+	elapsed := (end - start) / 1000000 /* nano to milli */
+	execResult = resolveWebResult(cmdMap[commons.USER_AUTH], execResult)
+	checkStatus := checkResult(cmdMap[commons.USER_AUTH], cmdMap[commons.FUNCTION_ID], param, status, execResult, result, false)
+
+	resultMap := commons.CopyMap(cmdMap)
+	resultMap[commons.PARAMETER] = recordedParam
+	resultMap[commons.CMD_STATUS] = status
+	resultMap[commons.CMD_RESULT] = execResult
+	resultMap[commons.CHECK_STATUS] = checkStatus
+	resultMap[commons.SUBMITTED_AT] = strconv.FormatInt(start, 10)
+	resultMap[commons.ENDED_AT] = strconv.FormatInt(end, 10)
+	resultMap[commons.ELAPSED_TIME] = strconv.FormatInt(elapsed, 10)
+	timing.addTo(resultMap)
+	processResult(resultMap)
+
+	return resultMap
+}
+
+func getResult(stopChan chan struct{}) {
 	for {
 		for idx := len(activationList) - 1; idx >= 0; idx-- {
 			resultMap := activationList[idx]
@@ -346,6 +511,7 @@ func getResult() {
 				resultMap[commons.ENDED_AT] = strconv.FormatInt(end, 10)
 				resultMap[commons.ELAPSED_TIME] = strconv.FormatInt(elapsed, 10)
 				processResult(resultMap)
+				atomic.AddInt32(&inFlightCount, -1)
 
 				lastIdx := len(activationList) - 1
 				activationList[idx] = activationList[lastIdx]
@@ -354,6 +520,10 @@ func getResult() {
 			}
 		}
 
-		time.Sleep(2 * time.Second)
+		select {
+		case <-stopChan:
+			return
+		case <-time.After(2 * time.Second):
+		}
 	}
 }
@@ -0,0 +1,353 @@
+package openwhisk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ParamSeed int64
+
+var paramRand *rand.Rand
+var paramMtx sync.Mutex
+
+/* parsed parameter templates, a workload has a handful of them for millions of invocations */
+var paramTemplateCache = make(map[string]map[string]interface{})
+
+var paramFuncRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z]+)((?:\s+[^\s}]+)*)\s*\}\}`)
+
+const payloadChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+/* ExecCmd hands ow-bench.sh the whole command as a single argument, the kernel caps one at MAX_ARG_STRLEN (128 KiB) */
+const maxArgLength = 128*1024 - 1
+
+/* a parameter starting with { is a JSON object, its values can call the template functions */
+func isParamTemplate(param string) bool {
+	return strings.HasPrefix(strings.TrimSpace(param), "{")
+}
+
+/*
+parseParamTemplate checks a JSON parameter & its template functions, every value (nested ones too) can use:
+{{randInt MIN MAX}}, {{pick A B C}}, {{seq}}, {{user}} & {{payload N}} (N random bytes). A value that's only a
+function call gets its type (randInt & seq are numbers), otherwise the results are put in the string.
+*/
+func parseParamTemplate(param string) (map[string]interface{}, error) {
+	paramMtx.Lock()
+	defer paramMtx.Unlock()
+
+	if template, ok := paramTemplateCache[param]; ok {
+		return template, nil
+	}
+
+	var template map[string]interface{}
+	if err := json.Unmarshal([]byte(param), &template); err != nil {
+		return nil, fmt.Errorf("Invalid parameter - %s is not a JSON object: %s", param, err)
+	}
+
+	if err := checkParamFuncs(template); err != nil {
+		return nil, err
+	}
+
+	paramTemplateCache[param] = template
+	return template, nil
+}
+
+func checkParamFuncs(value interface{}) error {
+	switch typedValue := value.(type) {
+	case string:
+		for _, match := range paramFuncRegex.FindAllStringSubmatch(typedValue, -1) {
+			if _, err := callParamFunc(match[1], strings.Fields(match[2]), 0, "", nil, false); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for _, nestedValue := range typedValue {
+			if err := checkParamFuncs(nestedValue); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, nestedValue := range typedValue {
+			if err := checkParamFuncs(nestedValue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+expandParam fills in the template of an invocation, returns the parameter to invoke the function with and the one to
+record, where payloads are left out as <N bytes> & commas become semicolons so the result columns stay intact. Plain
+parameters are returned as they are.
+*/
+func expandParam(param string, seq int, userID string) (string, string) {
+	if !isParamTemplate(param) {
+		return param, param
+	}
+
+	template, err := parseParamTemplate(param)
+	if err != nil {
+		panic(err)
+	}
+
+	paramMtx.Lock()
+	if paramRand == nil {
+		if ParamSeed == 0 {
+			ParamSeed = time.Now().UnixNano()
+		}
+		paramRand = rand.New(rand.NewSource(ParamSeed))
+	}
+	paramMtx.Unlock()
+
+	invokedValue, recordedValue := expandParamValue(template, seq, userID)
+	return toJsonString(invokedValue), strings.Replace(toJsonString(recordedValue), ",", ";", -1)
+}
+
+/* without the HTML escaping of json.Marshal, the <N bytes> of the payloads are kept readable */
+func toJsonString(value interface{}) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		panic(err)
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func expandParamValue(value interface{}, seq int, userID string) (interface{}, interface{}) {
+	switch typedValue := value.(type) {
+	case string:
+		return expandParamString(typedValue, seq, userID)
+	case map[string]interface{}:
+		/* in the order of the keys, so a seed always draws the same values for them */
+		keyArr := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keyArr = append(keyArr, key)
+		}
+		sort.Strings(keyArr)
+
+		invokedMap, recordedMap := make(map[string]interface{}), make(map[string]interface{})
+		for _, key := range keyArr {
+			invokedMap[key], recordedMap[key] = expandParamValue(typedValue[key], seq, userID)
+		}
+		return invokedMap, recordedMap
+	case []interface{}:
+		invokedArr, recordedArr := make([]interface{}, len(typedValue)), make([]interface{}, len(typedValue))
+		for idx, nestedValue := range typedValue {
+			invokedArr[idx], recordedArr[idx] = expandParamValue(nestedValue, seq, userID)
+		}
+		return invokedArr, recordedArr
+	}
+
+	return value, value
+}
+
+func expandParamString(value string, seq int, userID string) (interface{}, interface{}) {
+	matchArr := paramFuncRegex.FindAllStringSubmatchIndex(value, -1)
+	if len(matchArr) == 0 {
+		return value, value
+	}
+
+	paramMtx.Lock()
+	defer paramMtx.Unlock()
+
+	if len(matchArr) == 1 && matchArr[0][0] == 0 && matchArr[0][1] == len(value) {
+		funcName, argArr := value[matchArr[0][2]:matchArr[0][3]], strings.Fields(value[matchArr[0][4]:matchArr[0][5]])
+		result, _ := callParamFunc(funcName, argArr, seq, userID, paramRand, true)
+		if funcName == "payload" {
+			return result, "<" + argArr[0] + " bytes>"
+		}
+		return result, result
+	}
+
+	var invokedStr, recordedStr bytes.Buffer
+	lastIdx := 0
+	for _, match := range matchArr {
+		invokedStr.WriteString(value[lastIdx:match[0]])
+		recordedStr.WriteString(value[lastIdx:match[0]])
+
+		funcName, argArr := value[match[2]:match[3]], strings.Fields(value[match[4]:match[5]])
+		result, _ := callParamFunc(funcName, argArr, seq, userID, paramRand, true)
+		invokedStr.WriteString(fmt.Sprint(result))
+		if funcName == "payload" {
+			recordedStr.WriteString("<" + argArr[0] + " bytes>")
+		} else {
+			recordedStr.WriteString(fmt.Sprint(result))
+		}
+		lastIdx = match[1]
+	}
+	invokedStr.WriteString(value[lastIdx:])
+	recordedStr.WriteString(value[lastIdx:])
+
+	return invokedStr.String(), recordedStr.String()
+}
+
+/* a template function, only checks its arguments unless isCalled */
+func callParamFunc(funcName string, argArr []string, seq int, userID string, rnd *rand.Rand, isCalled bool) (interface{}, error) {
+	getIntArgs := func(count int) ([]int, error) {
+		if len(argArr) != count {
+			return nil, fmt.Errorf("Invalid parameter template - %s takes %d arguments, not %d", funcName, count, len(argArr))
+		}
+
+		intArr := make([]int, count)
+		for idx, arg := range argArr {
+			intVal, err := strconv.Atoi(arg)
+			if err != nil || intVal < 0 {
+				return nil, fmt.Errorf("Invalid parameter template - %s needs non-negative numbers, not %q", funcName, arg)
+			}
+			intArr[idx] = intVal
+		}
+		return intArr, nil
+	}
+
+	switch funcName {
+	case "randInt":
+		intArr, err := getIntArgs(2)
+		if err != nil {
+			return nil, err
+		}
+		if intArr[1] < intArr[0] {
+			return nil, fmt.Errorf("Invalid parameter template - randInt %d is greater than %d", intArr[0], intArr[1])
+		}
+		if !isCalled {
+			return nil, nil
+		}
+		return intArr[0] + rnd.Intn(intArr[1]-intArr[0]+1), nil
+	case "pick":
+		if len(argArr) == 0 {
+			return nil, fmt.Errorf("Invalid parameter template - pick needs at least a value")
+		}
+		if !isCalled {
+			return nil, nil
+		}
+		return toJsonValue(argArr[rnd.Intn(len(argArr))]), nil
+	case "seq":
+		if _, err := getIntArgs(0); err != nil {
+			return nil, err
+		}
+		return seq, nil
+	case "user":
+		if _, err := getIntArgs(0); err != nil {
+			return nil, err
+		}
+		return userID, nil
+	case "payload":
+		intArr, err := getIntArgs(1)
+		if err != nil {
+			return nil, err
+		}
+		if !isCalled {
+			return nil, nil
+		}
+		payloadBytes := make([]byte, intArr[0])
+		for idx := range payloadBytes {
+			payloadBytes[idx] = payloadChars[rnd.Intn(len(payloadChars))]
+		}
+		return string(payloadBytes), nil
+	}
+
+	return nil, fmt.Errorf("Invalid parameter template - unknown function %s", funcName)
+}
+
+/*
+the --param arguments of ow-bench.sh, a key & value per top level key of a JSON parameter. ow-bench.sh word-splits
+the command, so the quotes don't hold: the values can't have white space or glob characters (checkCliParam).
+*/
+func getParamArgs(param string) []string {
+	if param == "" {
+		return nil
+	}
+
+	if !isParamTemplate(param) {
+		return []string{"--param", param}
+	}
+
+	var paramMap map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(param), &paramMap); err != nil {
+		panic(fmt.Errorf("Invalid parameter - %s", err))
+	}
+
+	keyArr := make([]string, 0, len(paramMap))
+	for key := range paramMap {
+		keyArr = append(keyArr, key)
+	}
+	sort.Strings(keyArr)
+
+	var paramArr []string
+	for _, key := range keyArr {
+		value := string(paramMap[key])
+		var strValue string
+		if err := json.Unmarshal(paramMap[key], &strValue); err == nil {
+			/* wsk reads a value as JSON when it can, a plain string goes unquoted */
+			value = strValue
+		}
+		paramArr = append(paramArr, "--param", key, "'"+strings.Replace(value, "'", `'\''`, -1)+"'")
+	}
+
+	return paramArr
+}
+
+/*
+checkCliParam checks that a parameter template can go through ow-bench.sh: the filled in command has to fit in
+maxArgLength (a payload over about 128 KiB needs -invokeMode native) and, as the command is word-split, the values
+& keys can't have white space (it collapses) or glob characters (they expand to file names).
+*/
+func checkCliParam(param string) error {
+	template, err := parseParamTemplate(param)
+	if err != nil {
+		return err
+	}
+
+	length := len(param)
+	for _, match := range paramFuncRegex.FindAllStringSubmatch(param, -1) {
+		if match[1] == "payload" {
+			size, _ := strconv.Atoi(strings.TrimSpace(match[2]))
+			length += size
+		}
+	}
+	if length > maxArgLength {
+		return fmt.Errorf("Invalid parameter - about %d bytes is more than the %d bytes of an ow-bench.sh command, invoke it with -invokeMode native", length, maxArgLength)
+	}
+
+	return checkCliValue(template)
+}
+
+func checkCliValue(value interface{}) error {
+	switch typedValue := value.(type) {
+	case string:
+		literal := paramFuncRegex.ReplaceAllStringFunc(typedValue, func(match string) string {
+			/* the args of a call are split by spaces, only its values end up in the command */
+			return strings.Join(strings.Fields(paramFuncRegex.FindStringSubmatch(match)[2]), "")
+		})
+		if strings.ContainsAny(literal, " \t\n\r*?[") {
+			return fmt.Errorf("Invalid parameter - %q has white space or glob characters ow-bench.sh can't pass on, invoke it with -invokeMode native", typedValue)
+		}
+	case map[string]interface{}:
+		for key, nestedValue := range typedValue {
+			if err := checkCliValue(key); err != nil {
+				return err
+			}
+			if err := checkCliValue(nestedValue); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, nestedValue := range typedValue {
+			if err := checkCliValue(nestedValue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
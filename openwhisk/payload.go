@@ -0,0 +1,199 @@
+package openwhisk
+
+import (
+	"../commons"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const echoActionCode = "functions/echo.js"
+
+var PayloadSizes = "1KB,10KB,100KB,1MB,5MB"
+var PayloadMode = "echo"
+var PayloadRounds = 10
+var PayloadUser = "user_echo"
+
+var payloadOrderArr = []string{commons.USER_ID, commons.FUNCTION_ID, commons.PAYLOAD_SIZE, commons.SEQ, commons.CMD_RESULT, commons.ELAPSED_TIME, commons.SUBMITTED_AT, commons.ENDED_AT, commons.TRANSMITTED_BYTES, commons.RECEIVED_BYTES, commons.CMD_STATUS}
+var payloadCurveOrderArr = []string{commons.PAYLOAD_SIZE, commons.INVOCATIONS, commons.ERRORS, commons.TRANSMITTED_BYTES, commons.RECEIVED_BYTES, commons.MEAN_LATENCY, commons.P50_LATENCY, commons.P99_LATENCY}
+
+/* the request & response body sizes with the latency of the invocations of a payload size */
+type payloadProbes struct {
+	Errors           int
+	TransmittedBytes []float64
+	ReceivedBytes    []float64
+	Latencies        []float64
+}
+
+/*
+SweepPayloads measures how the latency scales with the argument & result size. The echo action (functions/echo.js,
+function 0 of PayloadUser) is invoked PayloadRounds times one after the other for every one of the PayloadSizes,
+through the REST API so the bodies are counted as they're sent: BytesTransmitted is the request & BytesReceived the
+response. In "echo" mode the payload is sent & returned, in "arg" mode only sent, in "result" mode only returned. The
+latency per size is written to _payload.csv at the end.
+*/
+func SweepPayloads(outputFilePath string, needCreation bool) {
+	if PayloadMode != "echo" && PayloadMode != "arg" && PayloadMode != "result" {
+		panic(fmt.Errorf("Invalid payload mode - %s", PayloadMode))
+	}
+
+	sizeArr := parsePayloadSizes(PayloadSizes)
+	if PayloadRounds <= 0 {
+		panic(fmt.Errorf("Invalid no. of rounds - %d", PayloadRounds))
+	}
+
+	usersVsFuncsMap := getPayloadFunctions()
+	if needCreation {
+		createUsers(map[string]struct{}{PayloadUser: {}})
+		deployFunctions(usersVsFuncsMap, []ActionSpec{{Function: 0, Code: echoActionCode}})
+	} else {
+		loadUserAuths(map[string]struct{}{PayloadUser: {}})
+	}
+	userAuth := userVsAuthMap[PayloadUser]
+
+	commons.PrintToStdOutOnVerbose("Sweeping " + strconv.Itoa(len(sizeArr)) + " payload sizes in " + PayloadMode + " mode, " + strconv.Itoa(PayloadRounds) + " invocations each:")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+	if outputFilePath != "" {
+		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
+	}
+
+	commons.PrintHeader(payloadOrderArr, outputFilePath)
+
+	/* warm up invocation, nothing to record */
+	param, _ := expandParam(getPayloadParam(sizeArr[0]), 0, PayloadUser)
+	invokeActionNative(userAuth, "0", param)
+
+	sizeVsProbesMap := make(map[int]*payloadProbes)
+	seq := 0
+	startRun = time.Now()
+	for _, size := range sizeArr {
+		probes := &payloadProbes{}
+		sizeVsProbesMap[size] = probes
+
+		for round := 0; round < PayloadRounds; round++ {
+			param, _ := expandParam(getPayloadParam(size), seq, PayloadUser)
+
+			start := time.Now().UnixNano()
+			status, execResult, transmittedBytes, receivedBytes, _ := invokeActionNative(userAuth, "0", param)
+			end := time.Now().UnixNano()
+
+			resultMap := make(map[string]string)
+			resultMap[commons.USER_ID] = PayloadUser
+			resultMap[commons.FUNCTION_ID] = "0"
+			resultMap[commons.PAYLOAD_SIZE] = strconv.Itoa(size)
+			resultMap[commons.SEQ] = strconv.Itoa(seq)
+			resultMap[commons.CMD_RESULT] = execResult
+			resultMap[commons.ELAPSED_TIME] = strconv.FormatInt((end-start)/1000000, 10)
+			resultMap[commons.SUBMITTED_AT] = strconv.FormatInt(start, 10)
+			resultMap[commons.ENDED_AT] = strconv.FormatInt(end, 10)
+			resultMap[commons.TRANSMITTED_BYTES] = strconv.Itoa(transmittedBytes)
+			resultMap[commons.RECEIVED_BYTES] = strconv.Itoa(receivedBytes)
+			resultMap[commons.CMD_STATUS] = status
+
+			if commons.WriteToFile {
+				commons.WriteMapToFile(resultMap, payloadOrderArr)
+			} else {
+				commons.WriteMapToOut(resultMap, payloadOrderArr)
+			}
+
+			probes.TransmittedBytes = append(probes.TransmittedBytes, float64(transmittedBytes))
+			probes.ReceivedBytes = append(probes.ReceivedBytes, float64(receivedBytes))
+			if status == "1" {
+				probes.Latencies = append(probes.Latencies, float64(end-start)/1000000)
+			} else {
+				probes.Errors++
+			}
+			seq++
+		}
+	}
+
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	commons.PrintToStdOutOnVerbose("Sweep completed in " + time.Since(startRun).String())
+	commons.OutputFileWriter.Close()
+
+	writePayloadCurve(outputFilePath, sizeArr, sizeVsProbesMap)
+	commons.WriteManifest(outputFilePath, echoActionCode, startRun)
+
+	doTeardown(usersVsFuncsMap, TeardownPolicy)
+}
+
+/* the echo action, function 0 of PayloadUser, is the only function of a sweep */
+func getPayloadFunctions() map[string]map[int]struct{} {
+	return map[string]map[int]struct{}{PayloadUser: {0: {}}}
+}
+
+/* the parameter template of a payload size for PayloadMode */
+func getPayloadParam(size int) string {
+	switch PayloadMode {
+	case "echo":
+		return `{"payload": "{{payload ` + strconv.Itoa(size) + `}}"}`
+	case "arg":
+		return `{"payload": "{{payload ` + strconv.Itoa(size) + `}}", "echo": false}`
+	case "result":
+		return `{"resultSize": ` + strconv.Itoa(size) + `}`
+	}
+
+	panic(fmt.Errorf("Invalid payload mode - %s", PayloadMode))
+}
+
+/* sizes in bytes, with an optional KB or MB (1024 based) suffix */
+func parsePayloadSizes(payloadSizes string) []int {
+	var sizeArr []int
+	for _, sizeStr := range strings.Split(payloadSizes, ",") {
+		sizeStr = strings.ToUpper(strings.TrimSpace(sizeStr))
+		multiplier := 1
+		if strings.HasSuffix(sizeStr, "KB") {
+			multiplier, sizeStr = 1024, strings.TrimSuffix(sizeStr, "KB")
+		} else if strings.HasSuffix(sizeStr, "MB") {
+			multiplier, sizeStr = 1024*1024, strings.TrimSuffix(sizeStr, "MB")
+		} else {
+			sizeStr = strings.TrimSuffix(sizeStr, "B")
+		}
+
+		size, err := strconv.Atoi(sizeStr)
+		if err != nil || size < 0 {
+			panic(fmt.Errorf("Invalid payload size - %s", sizeStr))
+		}
+		sizeArr = append(sizeArr, size*multiplier)
+	}
+
+	return sizeArr
+}
+
+/* mean request & response sizes with the latency percentiles of the successful invocations of every payload size */
+func writePayloadCurve(outputFilePath string, sizeArr []int, sizeVsProbesMap map[int]*payloadProbes) {
+	curveFilePath := ""
+	if outputFilePath != "" {
+		curveFilePath = strings.TrimSuffix(outputFilePath, ".csv") + "_payload.csv"
+		commons.OutputFileWriter = commons.CreateOutputFile(curveFilePath)
+	}
+
+	commons.PrintToStdOutOnVerbose("Latency (ms) vs payload size (bytes):")
+	commons.PrintHeader(payloadCurveOrderArr, curveFilePath)
+
+	for _, size := range sizeArr {
+		probes := sizeVsProbesMap[size]
+
+		curveMap := make(map[string]string)
+		curveMap[commons.PAYLOAD_SIZE] = strconv.Itoa(size)
+		curveMap[commons.INVOCATIONS] = strconv.Itoa(len(probes.TransmittedBytes))
+		curveMap[commons.ERRORS] = strconv.Itoa(probes.Errors)
+		curveMap[commons.TRANSMITTED_BYTES] = strconv.FormatFloat(commons.Mean(probes.TransmittedBytes), 'f', 0, 64)
+		curveMap[commons.RECEIVED_BYTES] = strconv.FormatFloat(commons.Mean(probes.ReceivedBytes), 'f', 0, 64)
+		if len(probes.Latencies) > 0 {
+			curveMap[commons.MEAN_LATENCY] = strconv.FormatFloat(commons.Mean(probes.Latencies), 'f', 1, 64)
+			curveMap[commons.P50_LATENCY] = strconv.FormatFloat(commons.Percentile(probes.Latencies, 50), 'f', 1, 64)
+			curveMap[commons.P99_LATENCY] = strconv.FormatFloat(commons.Percentile(probes.Latencies, 99), 'f', 1, 64)
+		}
+
+		if commons.WriteToFile {
+			commons.WriteMapToFile(curveMap, payloadCurveOrderArr)
+		} else {
+			commons.WriteMapToOut(curveMap, payloadCurveOrderArr)
+		}
+	}
+
+	commons.OutputFileWriter.Close()
+}
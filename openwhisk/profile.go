@@ -0,0 +1,340 @@
+package openwhisk
+
+import (
+	"../commons"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+/* how often a pause of the rate mode looks the level up again */
+const rateTick = 10 * time.Millisecond
+
+type LoadProfile struct {
+	Seed        int64       `yaml:"seed"`
+	MaxInFlight int         `yaml:"maxInFlight"`
+	Mix         []MixEntry  `yaml:"mix"`
+	MixFile     string      `yaml:"mixFile"`
+	Phases      []LoadPhase `yaml:"phases"`
+}
+
+type LoadPhase struct {
+	Name          string     `yaml:"name"`
+	Shape         string     `yaml:"shape"`
+	Mode          string     `yaml:"mode"`
+	Duration      int        `yaml:"duration"`
+	Level         float64    `yaml:"level"`
+	From          float64    `yaml:"from"`
+	To            float64    `yaml:"to"`
+	Steps         int        `yaml:"steps"`
+	SpikeAt       int        `yaml:"spikeAt"`
+	SpikeDuration int        `yaml:"spikeDuration"`
+	Amplitude     float64    `yaml:"amplitude"`
+	Period        int        `yaml:"period"`
+	Mix           []MixEntry `yaml:"mix"`
+	MixFile       string     `yaml:"mixFile"`
+}
+
+type MixEntry struct {
+	User     string  `yaml:"user"`
+	Function int     `yaml:"function"`
+	Param    string  `yaml:"param"`
+	Weight   float64 `yaml:"weight"`
+}
+
+func (obj LoadPhase) String() string {
+	return "LoadPhase: Name - " + obj.Name + ", Shape - " + obj.Shape + ", Mode - " + obj.Mode + ", Duration - " + strconv.Itoa(obj.Duration)
+}
+
+/* target rate (invocations/s) or concurrency of the phase after elapsed seconds */
+func (obj LoadPhase) levelAt(elapsed float64) float64 {
+	switch obj.Shape {
+	case "ramp":
+		return obj.From + (obj.To-obj.From)*elapsed/float64(obj.Duration)
+	case "step":
+		if obj.Steps == 1 {
+			return obj.From
+		}
+		stepIdx := math.Min(math.Floor(elapsed/(float64(obj.Duration)/float64(obj.Steps))), float64(obj.Steps-1))
+		return obj.From + (obj.To-obj.From)*stepIdx/float64(obj.Steps-1)
+	case "spike":
+		if elapsed >= float64(obj.SpikeAt) && elapsed < float64(obj.SpikeAt+obj.SpikeDuration) {
+			return obj.To
+		}
+		return obj.From
+	case "sine":
+		return math.Max(0, obj.Level+obj.Amplitude*math.Sin(2*math.Pi*elapsed/float64(obj.Period)))
+	default:
+		return obj.Level
+	}
+}
+
+/* weighted mix of invocations a phase draws from */
+type invocationMix struct {
+	Entries     []MixEntry
+	CumWeights  []float64
+	TotalWeight float64
+}
+
+func (obj invocationMix) pick(rnd *rand.Rand) MixEntry {
+	target := rnd.Float64() * obj.TotalWeight
+	idx := sort.SearchFloat64s(obj.CumWeights, target)
+	if idx >= len(obj.Entries) {
+		idx = len(obj.Entries) - 1
+	}
+
+	return obj.Entries[idx]
+}
+
+/*
+ExecLoadProfile runs the phases of the load profile (YAML) one after another. Each phase drives either an open loop
+arrival rate or a fixed no. of invocations in flight, shaped as constant, ramp, step, spike or sine over its duration,
+and draws its invocations from a weighted mix of user/function/param tuples. The phase name is written as a column
+of the results.
+*/
+func ExecLoadProfile(profileFilePath string, outputFilePath string, needCreation bool) {
+	runStartedAt := time.Now()
+	runLoadProfile(parseLoadProfile(profileFilePath), outputFilePath, needCreation)
+	commons.WriteManifest(outputFilePath, profileFilePath, runStartedAt)
+}
+
+func runLoadProfile(loadProfile LoadProfile, outputFilePath string, needCreation bool) {
+	phaseMixArr := make([]invocationMix, len(loadProfile.Phases))
+	var exists = struct{}{}
+	uniqueUsersList := make(map[string]struct{})
+	usersVsFuncsMap := make(map[string]map[int]struct{})
+
+	for idx, loadPhase := range loadProfile.Phases {
+		phaseMixArr[idx] = createInvocationMix(loadProfile, loadPhase)
+
+		for _, mixEntry := range phaseMixArr[idx].Entries {
+			uniqueUsersList[mixEntry.User] = exists
+			if _, ok := usersVsFuncsMap[mixEntry.User]; !ok {
+				usersVsFuncsMap[mixEntry.User] = make(map[int]struct{})
+			}
+			usersVsFuncsMap[mixEntry.User][mixEntry.Function] = exists
+		}
+	}
+
+	doInitialization(needCreation, uniqueUsersList, usersVsFuncsMap)
+
+	addPhaseColumn()
+
+	commons.PrintToStdOutOnVerbose("Running " + strconv.Itoa(len(loadProfile.Phases)) + " load phases:")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+	if outputFilePath != "" {
+		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
+	}
+
+	commons.PrintHeader(orderArr, outputFilePath)
+
+	runWarmup(getMixPairs(phaseMixArr))
+
+	seed := loadProfile.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+
+	var inFlight int32
+	totalExecCount := 0
+	startRun = time.Now()
+	timelineStopChan := startTimeline()
+
+	for idx, loadPhase := range loadProfile.Phases {
+		phaseExecCount := 0
+		startPhase := time.Now()
+		var lastDispatch time.Time
+		phaseDuration := time.Duration(loadPhase.Duration) * time.Second
+
+		for time.Since(startPhase) < phaseDuration {
+			level := loadPhase.levelAt(time.Since(startPhase).Seconds())
+
+			if loadPhase.Mode == "concurrency" {
+				if int(atomic.LoadInt32(&inFlight)) >= int(level) {
+					time.Sleep(time.Millisecond)
+					continue
+				}
+			} else {
+				if level <= 0 {
+					time.Sleep(rateTick)
+					lastDispatch = time.Time{}
+					continue
+				}
+
+				/* the pause ends at a tick or at the phase end to look the level up again, a ramp from 0 isn't slept through */
+				if lastDispatch.IsZero() {
+					lastDispatch = time.Now()
+				} else {
+					nextDispatch := lastDispatch.Add(time.Duration(float64(time.Second) / level))
+					if pause := time.Until(nextDispatch); pause > 0 {
+						if remaining := phaseDuration - time.Since(startPhase); remaining < pause {
+							pause = remaining
+						}
+						if pause > rateTick {
+							pause = rateTick
+						}
+						time.Sleep(pause)
+						continue
+					}
+
+					/* the schedule keeps its pace, but doesn't catch up in a burst when the level jumps */
+					lastDispatch = nextDispatch
+					if time.Since(lastDispatch) > rateTick {
+						lastDispatch = time.Now()
+					}
+				}
+
+				for loadProfile.MaxInFlight > 0 && int(atomic.LoadInt32(&inFlight)) >= loadProfile.MaxInFlight && time.Since(startPhase) < phaseDuration {
+					time.Sleep(time.Millisecond)
+				}
+				if time.Since(startPhase) >= phaseDuration {
+					break
+				}
+			}
+
+			mixEntry := phaseMixArr[idx].pick(rnd)
+			cmdMap := make(map[string]string)
+			cmdMap[commons.BATCH] = strconv.Itoa(idx)
+			cmdMap[commons.PHASE] = loadPhase.Name
+			cmdMap[commons.USER_ID] = mixEntry.User
+			cmdMap[commons.USER_AUTH] = userVsAuthMap[mixEntry.User]
+			cmdMap[commons.FUNCTION_ID] = strconv.Itoa(mixEntry.Function)
+			cmdMap[commons.PARAMETER] = mixEntry.Param
+			cmdMap[commons.SEQ] = strconv.Itoa(totalExecCount)
+
+			wgTime.Add(1)
+			atomic.AddInt32(&inFlight, 1)
+			phaseExecCount++
+			totalExecCount++
+
+			go func(cmdMap map[string]string) {
+				invokeAndProcess(cmdMap)
+				atomic.AddInt32(&inFlight, -1)
+				wgTime.Done()
+			}(cmdMap)
+		}
+
+		phaseElapse := time.Since(startPhase)
+		commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+		commons.PrintToStdOutOnVerbose("Phase " + loadPhase.Name + " submitted " + strconv.Itoa(phaseExecCount) + " executions in " + strconv.FormatFloat(phaseElapse.Seconds()*1000, 'f', 0, 64) + " ms (" + strconv.FormatFloat(float64(phaseExecCount)/phaseElapse.Seconds(), 'f', 2, 64) + "/s)")
+		commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	}
+
+	wgTime.Wait()
+
+	elapsed := time.Since(startRun)
+	elapsedTimeInMs := elapsed.Seconds() * 1000
+
+	commons.PrintToStdOutOnVerbose("Total time: " + strconv.FormatFloat(elapsedTimeInMs, 'f', 0, 64) + " ms")
+	commons.PrintToStdOutOnVerbose("Total executions: " + strconv.Itoa(totalExecCount))
+	commons.PrintToStdOutOnVerbose("Execution Rate: " + strconv.FormatFloat(float64(totalExecCount)/(elapsedTimeInMs/1000), 'f', 2, 64))
+	printSummary()
+
+	commons.OutputFileWriter.Close()
+	stopTimeline(timelineStopChan, outputFilePath)
+
+	doTeardown(usersVsFuncsMap, TeardownPolicy)
+}
+
+func parseLoadProfile(profileFilePath string) LoadProfile {
+	commons.PrintToStdOutOnVerbose("Parsing Profile: " + profileFilePath)
+
+	yamlFile, err := ioutil.ReadFile(profileFilePath)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	var loadProfile LoadProfile
+	err = yaml.Unmarshal(yamlFile, &loadProfile)
+	if err != nil {
+		panic(fmt.Errorf("Unmarshal: %v", err))
+	}
+
+	validateLoadProfile(loadProfile)
+	return loadProfile
+}
+
+func validateLoadProfile(loadProfile LoadProfile) {
+	if len(loadProfile.Phases) == 0 {
+		panic(fmt.Errorf("Load profile has no phases"))
+	}
+
+	for idx, loadPhase := range loadProfile.Phases {
+		if loadPhase.Name == "" {
+			panic(fmt.Errorf("Phase #%d has no name", idx))
+		}
+
+		if loadPhase.Duration <= 0 {
+			panic(fmt.Errorf("Invalid duration of phase %s - %d", loadPhase.Name, loadPhase.Duration))
+		}
+
+		if loadPhase.Mode != "rate" && loadPhase.Mode != "concurrency" {
+			panic(fmt.Errorf("Invalid mode of phase %s - %s", loadPhase.Name, loadPhase.Mode))
+		}
+
+		switch loadPhase.Shape {
+		case "", "constant", "ramp", "spike":
+		case "step":
+			if loadPhase.Steps <= 0 {
+				panic(fmt.Errorf("Step phase %s needs steps", loadPhase.Name))
+			}
+		case "sine":
+			if loadPhase.Period <= 0 {
+				panic(fmt.Errorf("Sine phase %s needs a period", loadPhase.Name))
+			}
+		default:
+			panic(fmt.Errorf("Invalid shape of phase %s - %s", loadPhase.Name, loadPhase.Shape))
+		}
+	}
+}
+
+/* the phase's own mix (or mix file) takes precedence over the one of the profile */
+func createInvocationMix(loadProfile LoadProfile, loadPhase LoadPhase) invocationMix {
+	mixEntries, mixFile := loadProfile.Mix, loadProfile.MixFile
+	if len(loadPhase.Mix) > 0 || loadPhase.MixFile != "" {
+		mixEntries, mixFile = loadPhase.Mix, loadPhase.MixFile
+	}
+
+	var phaseMix invocationMix
+	for _, mixEntry := range mixEntries {
+		mixEntry.User = getUserName(mixEntry.User)
+		if mixEntry.Weight == 0 {
+			mixEntry.Weight = 1
+		}
+
+		phaseMix.Entries = append(phaseMix.Entries, mixEntry)
+	}
+
+	if mixFile != "" {
+		batchVsUserFuncMap, _, _ := parseInputFile(mixFile)
+		for _, batchOfExecution := range getSortedBatches(batchVsUserFuncMap) {
+			for _, userFuncObj := range batchVsUserFuncMap[batchOfExecution] {
+				phaseMix.Entries = append(phaseMix.Entries, MixEntry{
+					User:     userFuncObj.UserID,
+					Function: userFuncObj.FunctionID,
+					Param:    userFuncObj.Param,
+					Weight:   float64(userFuncObj.NoOfTimesToExecute),
+				})
+			}
+		}
+	}
+
+	for _, mixEntry := range phaseMix.Entries {
+		phaseMix.TotalWeight += mixEntry.Weight
+		phaseMix.CumWeights = append(phaseMix.CumWeights, phaseMix.TotalWeight)
+	}
+
+	if phaseMix.TotalWeight <= 0 {
+		panic(fmt.Errorf("Phase %s has no invocations to draw from", loadPhase.Name))
+	}
+
+	return phaseMix
+}
@@ -0,0 +1,91 @@
+package openwhisk
+
+import (
+	"../analysis"
+	"../commons"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ResetPolicy = "none"
+
+/*
+RepeatRuns runs the workload file (execOWFile) or load profile (execOWProfile) Repetitions times, CoolDown seconds
+apart. Between two runs the functions ("actions") or the functions & users ("namespaces") can be reset, i.e. torn down
+& created again, so that every run starts cold. Every run writes <output>_run<N>.csv & the runs are aggregated into
+<output>_aggregate.csv.
+*/
+func RepeatRuns(command string, inputFilePath string, outputFilePath string, needCreation bool) {
+	if ResetPolicy != "none" && ResetPolicy != "actions" && ResetPolicy != "namespaces" {
+		panic(fmt.Errorf("Invalid reset policy - %s", ResetPolicy))
+	}
+
+	if outputFilePath == "" {
+		outputFilePath = commons.GenerateOutputFileName()
+	}
+	commons.WriteToFile = true
+
+	var usersVsFuncsMap map[string]map[int]struct{}
+	if command == "execOWProfile" {
+		usersVsFuncsMap = getScenarioFunctions(Scenario{}, parseLoadProfile(inputFilePath))
+	} else {
+		_, _, usersVsFuncsMap = parseInputFile(inputFilePath)
+	}
+
+	/* the functions & users are kept until the last run */
+	teardownPolicy := TeardownPolicy
+	defer func() {
+		TeardownPolicy = teardownPolicy
+	}()
+
+	var resultFileArr []string
+	for run := 1; run <= Repetitions; run++ {
+		if run > 1 {
+			coolDown()
+			resetBetweenRuns(usersVsFuncsMap)
+		}
+
+		TeardownPolicy = "none"
+		if run == Repetitions {
+			TeardownPolicy = teardownPolicy
+		}
+
+		commons.PrintToStdOutOnVerbose("Run " + strconv.Itoa(run) + " of " + strconv.Itoa(Repetitions))
+
+		resultFilePath := strings.TrimSuffix(outputFilePath, ".csv") + "_run" + strconv.Itoa(run) + ".csv"
+		resetRunState()
+		if command == "execOWProfile" {
+			ExecLoadProfile(inputFilePath, resultFilePath, needCreation && run == 1)
+		} else {
+			ExecCmdsFromFile(inputFilePath, resultFilePath, needCreation && run == 1)
+		}
+		resultFileArr = append(resultFileArr, resultFilePath)
+	}
+
+	analysis.WriteAggregate(resultFileArr, strings.TrimSuffix(outputFilePath, ".csv")+"_aggregate.csv")
+}
+
+/* tear down the functions (and users) of the previous run as ResetPolicy says & create them again */
+func resetBetweenRuns(usersVsFuncsMap map[string]map[int]struct{}) {
+	if ResetPolicy == "none" {
+		return
+	}
+
+	doTeardown(usersVsFuncsMap, ResetPolicy)
+
+	if ResetPolicy == "namespaces" {
+		var exists = struct{}{}
+		uniqueUsersList := make(map[string]struct{})
+		for user := range usersVsFuncsMap {
+			uniqueUsersList[user] = exists
+		}
+		createUsers(uniqueUsersList)
+	}
+
+	if ActionSpecFile != "" {
+		deployFunctions(usersVsFuncsMap, parseActionSpecs(ActionSpecFile))
+	} else {
+		createFunctions(usersVsFuncsMap)
+	}
+}
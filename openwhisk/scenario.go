@@ -0,0 +1,204 @@
+package openwhisk
+
+import (
+	"../commons"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Scenario struct {
+	Name       string       `yaml:"name"`
+	Namespaces []string     `yaml:"namespaces"`
+	Actions    []ActionSpec `yaml:"actions"`
+	Workload   WorkloadSpec `yaml:"workload"`
+	Output     OutputSpec   `yaml:"output"`
+	Teardown   string       `yaml:"teardown"`
+}
+
+type WorkloadSpec struct {
+	File        string       `yaml:"file"`
+	Profile     string       `yaml:"profile"`
+	Load        *LoadProfile `yaml:"load"`
+	Concurrency int          `yaml:"concurrency"`
+	RateLimit   float64      `yaml:"rateLimit"`
+	BatchDelay  int          `yaml:"batchDelay"`
+	Async       bool         `yaml:"async"`
+}
+
+type OutputSpec struct {
+	File    string `yaml:"file"`
+	Verbose *bool  `yaml:"verbose"`
+}
+
+/*
+RunScenario executes a scenario file (YAML or JSON) end to end: creates its namespaces, deploys its actions with the
+given code, kind, memory, timeout & params (an action with namespaces is deployed for those users only, over the
+action of the same function without), runs the workload (a batch CSV file or a load profile) with the output
+settings of the scenario and finally tears down the actions ("actions") or the namespaces too ("namespaces").
+*/
+func RunScenario(scenarioFilePath string, outputFilePath string) {
+	scenario := parseScenario(scenarioFilePath)
+	commons.PrintToStdOutOnVerbose("Running Scenario: " + scenario.Name)
+
+	if scenario.Workload.Concurrency > 0 {
+		commons.ConcurrencyFactor = scenario.Workload.Concurrency
+	}
+
+	if scenario.Workload.RateLimit > 0 {
+		commons.RateLimit = scenario.Workload.RateLimit
+	}
+
+	if scenario.Workload.BatchDelay > 0 {
+		commons.BatchDelay = scenario.Workload.BatchDelay
+	}
+
+	if scenario.Workload.Async {
+		IsAsync = true
+	}
+
+	if scenario.Output.Verbose != nil {
+		commons.Verbose = *scenario.Output.Verbose
+	}
+
+	if scenario.Output.File != "" {
+		commons.WriteToFile = true
+		outputFilePath = scenario.Output.File
+	}
+
+	var loadProfile LoadProfile
+	if scenario.Workload.Profile != "" {
+		loadProfile = parseLoadProfile(scenario.Workload.Profile)
+	} else if scenario.Workload.Load != nil {
+		loadProfile = *scenario.Workload.Load
+		validateLoadProfile(loadProfile)
+	}
+
+	usersVsFuncsMap := getScenarioFunctions(scenario, loadProfile)
+	setUpScenario(scenario, usersVsFuncsMap)
+
+	/* the scenario's own policy (or the -teardown one) is applied once its workload is done */
+	teardownPolicy := TeardownPolicy
+	if scenario.Teardown != "" {
+		teardownPolicy = scenario.Teardown
+	}
+	TeardownPolicy = "none"
+
+	startTime := time.Now()
+	if scenario.Workload.File != "" {
+		/* the manifest of the run is the one of the scenario */
+		runWorkloadFile(scenario.Workload.File, outputFilePath, false)
+	} else {
+		runLoadProfile(loadProfile, outputFilePath, false)
+	}
+	commons.PrintToStdOutOnVerbose("Scenario " + scenario.Name + " completed in " + time.Since(startTime).String())
+	commons.WriteManifest(outputFilePath, scenarioFilePath, startTime)
+
+	doTeardown(usersVsFuncsMap, teardownPolicy)
+}
+
+func parseScenario(scenarioFilePath string) Scenario {
+	commons.PrintToStdOutOnVerbose("Parsing Scenario: " + scenarioFilePath)
+
+	scenarioFile, err := ioutil.ReadFile(scenarioFilePath)
+	if err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	var scenario Scenario
+	err = yaml.Unmarshal(scenarioFile, &scenario)
+	if err != nil {
+		panic(fmt.Errorf("Unmarshal: %v", err))
+	}
+
+	if scenario.Name == "" {
+		scenario.Name = strings.TrimSuffix(filepath.Base(scenarioFilePath), filepath.Ext(scenarioFilePath))
+	}
+
+	sourceCount := 0
+	for _, source := range []bool{scenario.Workload.File != "", scenario.Workload.Profile != "", scenario.Workload.Load != nil} {
+		if source {
+			sourceCount++
+		}
+	}
+
+	if sourceCount != 1 {
+		panic(fmt.Errorf("Scenario %s needs exactly one of workload file, profile or load", scenario.Name))
+	}
+
+	switch scenario.Teardown {
+	case "", "none", "actions", "namespaces":
+	default:
+		panic(fmt.Errorf("Invalid teardown policy of scenario %s - %s", scenario.Name, scenario.Teardown))
+	}
+
+	/* conflicting actions are caught before any namespace is set up */
+	getSpecMap(scenario.Actions)
+
+	return scenario
+}
+
+/* users & their functions the scenario needs: its own namespaces & actions along with the ones its workload invokes */
+func getScenarioFunctions(scenario Scenario, loadProfile LoadProfile) map[string]map[int]struct{} {
+	var exists = struct{}{}
+	usersVsFuncsMap := make(map[string]map[int]struct{})
+
+	addFunction := func(user string, function int) {
+		if _, ok := usersVsFuncsMap[user]; !ok {
+			usersVsFuncsMap[user] = make(map[int]struct{})
+		}
+
+		if function >= 0 {
+			usersVsFuncsMap[user][function] = exists
+		}
+	}
+
+	for _, namespace := range scenario.Namespaces {
+		addFunction(getUserName(namespace), -1)
+	}
+
+	if scenario.Workload.File != "" {
+		_, _, fileUsersVsFuncsMap := parseInputFile(scenario.Workload.File)
+		for user, funcList := range fileUsersVsFuncsMap {
+			for function := range funcList {
+				addFunction(user, function)
+			}
+		}
+	} else {
+		for _, loadPhase := range loadProfile.Phases {
+			for _, mixEntry := range createInvocationMix(loadProfile, loadPhase).Entries {
+				addFunction(mixEntry.User, mixEntry.Function)
+			}
+		}
+	}
+
+	for _, actionSpec := range scenario.Actions {
+		if len(actionSpec.Namespaces) == 0 {
+			for user := range usersVsFuncsMap {
+				addFunction(user, actionSpec.Function)
+			}
+			continue
+		}
+
+		for _, namespace := range actionSpec.Namespaces {
+			addFunction(getUserName(namespace), actionSpec.Function)
+		}
+	}
+
+	return usersVsFuncsMap
+}
+
+/* create the namespaces & deploy every action with its spec (or the default trial function if it has none) */
+func setUpScenario(scenario Scenario, usersVsFuncsMap map[string]map[int]struct{}) {
+	var exists = struct{}{}
+	uniqueUsersList := make(map[string]struct{})
+	for user := range usersVsFuncsMap {
+		uniqueUsersList[user] = exists
+	}
+
+	createUsers(uniqueUsersList)
+	deployFunctions(usersVsFuncsMap, scenario.Actions)
+}
@@ -0,0 +1,160 @@
+package openwhisk
+
+import (
+	"../commons"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var SessionMode = false
+var ThinkTime = "fixed:0"
+
+/* think time (ms) before an invocation of a session */
+type thinkTimeDist struct {
+	Kind string
+	Min  float64
+	Max  float64
+	Mean float64
+}
+
+/* "fixed:MS", "uniform:MIN-MAX" or "exp:MEAN" (exponential, the think time of independent users), all in ms */
+func parseThinkTime(spec string) thinkTimeDist {
+	specParts := strings.Split(spec, ":")
+
+	var dist thinkTimeDist
+	var err error
+	switch {
+	case specParts[0] == "fixed" && len(specParts) == 2:
+		dist.Min, err = strconv.ParseFloat(specParts[1], 64)
+		dist.Max = dist.Min
+	case specParts[0] == "uniform" && len(specParts) == 2:
+		rangeParts := strings.Split(specParts[1], "-")
+		if len(rangeParts) != 2 {
+			err = fmt.Errorf("range is not MIN-MAX")
+			break
+		}
+		dist.Min, err = strconv.ParseFloat(rangeParts[0], 64)
+		if err == nil {
+			dist.Max, err = strconv.ParseFloat(rangeParts[1], 64)
+		}
+	case specParts[0] == "exp" && len(specParts) == 2:
+		dist.Mean, err = strconv.ParseFloat(specParts[1], 64)
+		if err == nil && dist.Mean <= 0 {
+			err = fmt.Errorf("the mean needs to be more than 0")
+		}
+	default:
+		err = fmt.Errorf("unknown distribution")
+	}
+
+	if err == nil && (dist.Min < 0 || dist.Max < dist.Min) {
+		err = fmt.Errorf("invalid range")
+	}
+
+	if err != nil {
+		panic(fmt.Errorf("Invalid think time %s - %s", spec, err))
+	}
+
+	dist.Kind = specParts[0]
+	return dist
+}
+
+func (obj thinkTimeDist) sample(rnd *rand.Rand) time.Duration {
+	var thinkTimeInMs float64
+	switch obj.Kind {
+	case "exp":
+		thinkTimeInMs = rnd.ExpFloat64() * obj.Mean
+	case "uniform":
+		thinkTimeInMs = obj.Min + rnd.Float64()*(obj.Max-obj.Min)
+	default:
+		thinkTimeInMs = obj.Min
+	}
+
+	return time.Duration(thinkTimeInMs * float64(time.Millisecond))
+}
+
+/* the think time column is added once, for the rows of the sessions */
+func addThinkTimeColumn() {
+	if !commons.ValueInSlice(commons.THINK_TIME, orderArr) {
+		orderArr = append(orderArr, commons.THINK_TIME)
+	}
+}
+
+/*
+dispatchSessions runs every batch as closed loop sessions instead of handing the invocations to the co-routines: each
+user of the batch gets its own virtual client that invokes its functions one after the other, in the order of the
+workload, waiting for the result & a think time (ThinkTime) before the next one. So a user never has more than one
+invocation in flight, and as many sessions run at once as there are users in the batch, whatever -cf is. The
+-rateLimit holds the sessions back like it does the co-routines.
+*/
+func dispatchSessions(batchVsUserFuncMap map[int][]UserFuncs, totalExecCount int) int {
+	thinkTime := parseThinkTime(ThinkTime)
+
+	paramMtx.Lock()
+	if ParamSeed == 0 {
+		ParamSeed = time.Now().UnixNano()
+	}
+	paramMtx.Unlock()
+
+	for _, batchOfExecution := range getSortedBatches(batchVsUserFuncMap) {
+		userVsRecordsMap := make(map[string][]invocationRecord)
+		for _, userFuncObj := range batchVsUserFuncMap[batchOfExecution] {
+			for i := 1; i <= userFuncObj.NoOfTimesToExecute; i++ {
+				userVsRecordsMap[userFuncObj.UserID] = append(userVsRecordsMap[userFuncObj.UserID], createInvocationRecord(userFuncObj, totalExecCount))
+				totalExecCount++
+			}
+		}
+
+		userArr := make([]string, 0, len(userVsRecordsMap))
+		for user := range userVsRecordsMap {
+			userArr = append(userArr, user)
+		}
+		sort.Strings(userArr)
+
+		startBatch := time.Now()
+		batchExecCount := 0
+		for _, user := range userArr {
+			wgTime.Add(1)
+			batchExecCount += len(userVsRecordsMap[user])
+			go runSession(userVsRecordsMap[user], thinkTime, rand.New(rand.NewSource(getSessionSeed(user, batchOfExecution))))
+		}
+
+		completeBatch(batchOfExecution, batchExecCount, startBatch)
+	}
+
+	return totalExecCount
+}
+
+/* a generator per session, seeded so that a seed gives a user the same think times in a batch from run to run */
+func getSessionSeed(user string, batchOfExecution int) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(user))
+
+	return ParamSeed ^ int64(hash.Sum64()) + int64(batchOfExecution)
+}
+
+func runSession(recordArr []invocationRecord, thinkTime thinkTimeDist, rnd *rand.Rand) {
+	for idx, record := range recordArr {
+		var thinkDuration time.Duration
+		if idx > 0 {
+			thinkDuration = thinkTime.sample(rnd)
+			time.Sleep(thinkDuration)
+		}
+
+		/* sessions don't go through dispatchInvocation, the rate limit holds back each of their invocations */
+		holdForRateLimit()
+
+		cmdMap := record.getCmdMap()
+		cmdMap[commons.THINK_TIME] = strconv.FormatInt(int64(thinkDuration/time.Millisecond), 10)
+		resultMap := invokeAndProcess(cmdMap)
+		if strings.HasPrefix(resultMap[commons.CMD_RESULT], "error") {
+			panic(fmt.Errorf("Error during execution - %s", resultMap[commons.CMD_RESULT]))
+		}
+	}
+
+	wgTime.Done()
+}
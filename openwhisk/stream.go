@@ -0,0 +1,236 @@
+package openwhisk
+
+import (
+	"../commons"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const STDIN_WORKLOAD = "-"
+
+var StreamWorkload = false
+
+/* an invocation on its way to the co-routines, the result map is only created once it's executed */
+type invocationRecord struct {
+	Batch      int
+	Seq        int
+	Phase      string
+	UserID     string
+	UserAuth   string
+	FunctionID int
+	Param      string
+}
+
+func (obj invocationRecord) getCmdMap() map[string]string {
+	cmdMap := make(map[string]string)
+	cmdMap[commons.BATCH] = strconv.Itoa(obj.Batch)
+	cmdMap[commons.PHASE] = obj.Phase
+	cmdMap[commons.USER_ID] = obj.UserID
+	cmdMap[commons.USER_AUTH] = obj.UserAuth
+	cmdMap[commons.FUNCTION_ID] = strconv.Itoa(obj.FunctionID)
+	cmdMap[commons.PARAMETER] = obj.Param
+	cmdMap[commons.SEQ] = strconv.Itoa(obj.Seq)
+
+	return cmdMap
+}
+
+type workloadReader struct {
+	io.Reader
+	closerArr []io.Closer
+}
+
+func (obj workloadReader) Close() error {
+	for idx := len(obj.closerArr) - 1; idx >= 0; idx-- {
+		obj.closerArr[idx].Close()
+	}
+
+	return nil
+}
+
+/* open a workload file, "-" for stdin, gunzipped when it's gzip compressed (whatever its name) */
+func openWorkload(inputFilePath string) io.ReadCloser {
+	var fread io.ReadCloser = os.Stdin
+	if inputFilePath != STDIN_WORKLOAD {
+		file, err := os.Open(inputFilePath)
+		if err != nil {
+			panic(fmt.Errorf("File error - %s", err))
+		}
+		fread = file
+	}
+
+	bufReader := bufio.NewReader(fread)
+	magicBytes, _ := bufReader.Peek(2)
+	if len(magicBytes) < 2 || magicBytes[0] != 0x1f || magicBytes[1] != 0x8b {
+		return workloadReader{Reader: bufReader, closerArr: []io.Closer{fread}}
+	}
+
+	gzipReader, err := gzip.NewReader(bufReader)
+	if err != nil {
+		fread.Close()
+		panic(fmt.Errorf("File error - %s: %s", inputFilePath, err))
+	}
+
+	return workloadReader{Reader: gzipReader, closerArr: []io.Closer{fread, gzipReader}}
+}
+
+/* parse every line of the workload in the file order, a bad line panics with its file:line */
+func readWorkload(inputFilePath string, handleLine func(userFuncObj UserFuncs, lineNo int)) {
+	fread := openWorkload(inputFilePath)
+	defer fread.Close()
+
+	scanner := bufio.NewScanner(fread)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		userFuncObj, err := parseUserFuncs(strings.Split(scanner.Text(), ","))
+		if err != nil {
+			panic(fmt.Errorf("%s:%d: %s", inputFilePath, lineNo, err))
+		}
+
+		handleLine(userFuncObj, lineNo)
+	}
+
+	if err := scanner.Err(); err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+}
+
+/*
+scanWorkload goes through the workload once for its users & functions, without keeping the lines, and tells whether
+its batches are in order so it can be streamed. It's only done when the users & functions are needed up front (to
+create them or warm them up), otherwise they are found while streaming, so the first batch starts right away. Stdin
+can't be read twice, it's never scanned.
+*/
+func scanWorkload(inputFilePath string, isScanNeeded bool) (map[string]struct{}, map[string]map[int]struct{}, bool) {
+	uniqueUsersList := make(map[string]struct{})
+	usersVsFuncsMap := make(map[string]map[int]struct{})
+	if inputFilePath == STDIN_WORKLOAD || !isScanNeeded {
+		return uniqueUsersList, usersVsFuncsMap, true
+	}
+
+	commons.PrintToStdOutOnVerbose("Scanning File: " + inputFilePath)
+	isOrdered := true
+	lastBatch := -1
+	readWorkload(inputFilePath, func(userFuncObj UserFuncs, lineNo int) {
+		if userFuncObj.Time < lastBatch {
+			isOrdered = false
+		}
+		lastBatch = userFuncObj.Time
+		addUserFunc(uniqueUsersList, usersVsFuncsMap, userFuncObj)
+	})
+
+	return uniqueUsersList, usersVsFuncsMap, isOrdered
+}
+
+func addUserFunc(uniqueUsersList map[string]struct{}, usersVsFuncsMap map[string]map[int]struct{}, userFuncObj UserFuncs) {
+	var exists = struct{}{}
+	uniqueUsersList[userFuncObj.UserID] = exists
+
+	uniqueFuncList, ok := usersVsFuncsMap[userFuncObj.UserID]
+	if !ok {
+		uniqueFuncList = make(map[int]struct{})
+		usersVsFuncsMap[userFuncObj.UserID] = uniqueFuncList
+	}
+	uniqueFuncList[userFuncObj.FunctionID] = exists
+}
+
+/* the (user, function) pairs of a scanned workload, used to warm up a streamed run */
+func getFuncPairs(usersVsFuncsMap map[string]map[int]struct{}) []UserFuncs {
+	var pairArr []UserFuncs
+	for user, funcList := range usersVsFuncsMap {
+		for functionID := range funcList {
+			pairArr = append(pairArr, UserFuncs{UserID: user, FunctionID: functionID})
+		}
+	}
+
+	sort.Slice(pairArr, func(i, j int) bool {
+		if pairArr[i].UserID != pairArr[j].UserID {
+			return pairArr[i].UserID < pairArr[j].UserID
+		}
+		return pairArr[i].FunctionID < pairArr[j].FunctionID
+	})
+
+	return pairArr
+}
+
+/*
+streamBatches dispatches the invocations of a workload ordered by batch while it's read, a batch is complete when the
+first line of the next one comes up. Only the current line is held in memory. The users that weren't scanned have their
+auth loaded when first seen, and are added to usersVsFuncsMap for the teardown.
+*/
+func streamBatches(inputFilePath string, totalExecCount int, usersVsFuncsMap map[string]map[int]struct{}) int {
+	uniqueUsersList := make(map[string]struct{})
+	currBatch, batchExecCount := -1, 0
+	var startBatch time.Time
+	loadedCount := 0
+
+	readWorkload(inputFilePath, func(userFuncObj UserFuncs, lineNo int) {
+		if userFuncObj.Time < currBatch {
+			panic(fmt.Errorf("%s:%d: batch %d comes after batch %d, a workload needs to be ordered by batch to be streamed (or run without -stream)", inputFilePath, lineNo, userFuncObj.Time, currBatch))
+		}
+
+		if userFuncObj.Time != currBatch {
+			if currBatch >= 0 {
+				completeBatch(currBatch, batchExecCount, startBatch)
+			}
+			currBatch, batchExecCount = userFuncObj.Time, 0
+			startBatch = time.Now()
+		}
+
+		counterMtx.Lock()
+		_, isLoaded := userVsAuthMap[userFuncObj.UserID]
+		counterMtx.Unlock()
+		if !isLoaded {
+			loadStreamedUserAuth(userFuncObj.UserID)
+			loadedCount++
+		}
+		addUserFunc(uniqueUsersList, usersVsFuncsMap, userFuncObj)
+
+		for i := 1; i <= userFuncObj.NoOfTimesToExecute; i++ {
+			dispatchInvocation(createInvocationRecord(userFuncObj, totalExecCount))
+			batchExecCount++
+			totalExecCount++
+		}
+	})
+
+	if currBatch >= 0 {
+		completeBatch(currBatch, batchExecCount, startBatch)
+	}
+
+	if loadedCount > 0 {
+		saveAuthCache()
+		commons.PrintToStdOutOnVerbose(strconv.Itoa(loadedCount) + " users are loaded with their auth details while streaming")
+	}
+
+	return totalExecCount
+}
+
+/*
+loadStreamedUserAuth looks up the auth of a user first seen while streaming, from the auth cache if possible. It runs
+in line, without waiting for the invocations in flight, and leaves the cache to be saved once the stream is done.
+*/
+func loadStreamedUserAuth(user string) {
+	readAuthCache()
+
+	counterMtx.Lock()
+	entry, isCached := authCache[getAuthCacheHost()][user]
+	counterMtx.Unlock()
+
+	userAuth := entry.Auth
+	isFresh := time.Since(entry.SavedAt) < time.Duration(AuthCacheTTL)*time.Hour
+	if !isCached || (!isFresh && !isValidAuth(user, entry.Auth)) {
+		userAuth = doExecAndParse([]string{"getUserAuth", user}, 10)
+	}
+	cacheUserAuth(user, userAuth)
+
+	counterMtx.Lock()
+	userVsAuthMap[user] = userAuth
+	counterMtx.Unlock()
+}
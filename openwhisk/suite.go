@@ -0,0 +1,131 @@
+package openwhisk
+
+import (
+	"../analysis"
+	"../commons"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ExperimentDir = "experiments"
+var Repetitions = 1
+var CoolDown = 0
+
+var scalingOrderArr = []string{commons.TRIAL, commons.USERS, commons.RUNS, commons.INVOCATIONS, commons.ERRORS, commons.THROUGHPUT, commons.P50_LATENCY, commons.P99_LATENCY}
+
+/*
+RunSuite runs every trial (workload file) of a directory like trials/nop, in the order of their no. of users, Repetitions
+times each with CoolDown seconds (and a reset as ResetPolicy says) between the runs. The results go to a new directory
+under ExperimentDir, one file per trial & run named <trial>_run<N>.csv so that two experiments can be compared
+directly, and the suite ends with the scaling of the throughput & latency with the users across the trials.
+*/
+func RunSuite(suiteDirPath string, needCreation bool) {
+	trialFileArr, _ := filepath.Glob(filepath.Join(suiteDirPath, "*.csv"))
+	if len(trialFileArr) == 0 {
+		panic(fmt.Errorf("No trials in %s", suiteDirPath))
+	}
+
+	sort.Slice(trialFileArr, func(i, j int) bool {
+		return analysis.IsNaturallyLess(filepath.Base(trialFileArr[i]), filepath.Base(trialFileArr[j]))
+	})
+
+	suiteName := filepath.Base(filepath.Clean(suiteDirPath))
+	experimentPath := filepath.Join(ExperimentDir, suiteName+"_"+time.Now().Format("20060102_150405"))
+	if err := os.MkdirAll(experimentPath, 0755); err != nil {
+		panic(fmt.Errorf("Cannot create directory - %s", err))
+	}
+
+	commons.PrintToStdOutOnVerbose("Running " + strconv.Itoa(len(trialFileArr)) + " trials of " + suiteName + " " + strconv.Itoa(Repetitions) + " times each into " + experimentPath)
+	commons.WriteToFile = true
+
+	/* the functions & users are kept until the last run of a trial */
+	teardownPolicy := TeardownPolicy
+	defer func() {
+		TeardownPolicy = teardownPolicy
+	}()
+
+	trialVsResultsMap := make(map[string][]string)
+	for trialIdx, trialFilePath := range trialFileArr {
+		trialName := strings.TrimSuffix(filepath.Base(trialFilePath), ".csv")
+		for run := 1; run <= Repetitions; run++ {
+			if trialIdx > 0 || run > 1 {
+				coolDown()
+			}
+
+			if run > 1 {
+				_, _, usersVsFuncsMap := parseInputFile(trialFilePath)
+				resetBetweenRuns(usersVsFuncsMap)
+			}
+
+			commons.PrintToStdOutOnVerbose("Trial " + trialName + ", run " + strconv.Itoa(run) + " of " + strconv.Itoa(Repetitions))
+
+			resultFilePath := filepath.Join(experimentPath, trialName+"_run"+strconv.Itoa(run)+".csv")
+			TeardownPolicy = "none"
+			if run == Repetitions {
+				TeardownPolicy = teardownPolicy
+			}
+
+			resetRunState()
+			ExecCmdsFromFile(trialFilePath, resultFilePath, needCreation && run == 1)
+			trialVsResultsMap[trialName] = append(trialVsResultsMap[trialName], resultFilePath)
+		}
+
+		if Repetitions > 1 {
+			analysis.WriteAggregate(trialVsResultsMap[trialName], filepath.Join(experimentPath, trialName+"_aggregate.csv"))
+		}
+	}
+
+	writeScaling(trialFileArr, trialVsResultsMap, filepath.Join(experimentPath, suiteName+"_scaling.csv"))
+}
+
+func coolDown() {
+	if CoolDown <= 0 {
+		return
+	}
+
+	commons.PrintToStdOutOnVerbose("Cooling down for " + strconv.Itoa(CoolDown) + " s")
+	time.Sleep(time.Duration(CoolDown) * time.Second)
+}
+
+/* users vs throughput & latency of the trials, averaged over their runs */
+func writeScaling(trialFileArr []string, trialVsResultsMap map[string][]string, scalingFilePath string) {
+	commons.OutputFileWriter = commons.CreateOutputFile(scalingFilePath)
+	commons.PrintToStdOutOnVerbose("Scaling across the trials:")
+	commons.PrintHeader(scalingOrderArr, scalingFilePath)
+
+	for _, trialFilePath := range trialFileArr {
+		trialName := strings.TrimSuffix(filepath.Base(trialFilePath), ".csv")
+
+		var throughputArr, p50Arr, p99Arr []float64
+		invocationCount, errorCount, userCount := 0, 0, 0
+		for _, resultFilePath := range trialVsResultsMap[trialName] {
+			invocationArr := analysis.GetMeasured(analysis.ReadResultFile(resultFilePath))
+			stats := analysis.GetRunStats(invocationArr)
+
+			invocationCount += stats.Count
+			errorCount += stats.Errors
+			userCount = len(analysis.GetUserStats(invocationArr))
+			throughputArr = append(throughputArr, stats.Throughput)
+			p50Arr = append(p50Arr, stats.P50)
+			p99Arr = append(p99Arr, stats.P99)
+		}
+
+		scalingMap := make(map[string]string)
+		scalingMap[commons.TRIAL] = trialName
+		scalingMap[commons.USERS] = strconv.Itoa(userCount)
+		scalingMap[commons.RUNS] = strconv.Itoa(len(trialVsResultsMap[trialName]))
+		scalingMap[commons.INVOCATIONS] = strconv.Itoa(invocationCount)
+		scalingMap[commons.ERRORS] = strconv.Itoa(errorCount)
+		scalingMap[commons.THROUGHPUT] = strconv.FormatFloat(commons.Mean(throughputArr), 'f', 2, 64)
+		scalingMap[commons.P50_LATENCY] = strconv.FormatFloat(commons.Mean(p50Arr), 'f', 0, 64)
+		scalingMap[commons.P99_LATENCY] = strconv.FormatFloat(commons.Mean(p99Arr), 'f', 0, 64)
+		commons.WriteMapToFile(scalingMap, scalingOrderArr)
+	}
+
+	commons.OutputFileWriter.Close()
+}
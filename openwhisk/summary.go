@@ -0,0 +1,83 @@
+package openwhisk
+
+import (
+	"../commons"
+	"strconv"
+	"strings"
+)
+
+type runSummary struct {
+	Elapsed      []float64
+	Wait         []float64
+	Init         []float64
+	Run          []float64
+	TTFB         []float64
+	Errors       int
+	ColdStarts   int
+	FailedChecks int
+}
+
+var measuredSummary runSummary
+
+/* record the timings of a measured invocation, warm up invocations are left out */
+func recordSummary(resultMap map[string]string) {
+	if resultMap[commons.PHASE] == WARMUP_PHASE {
+		return
+	}
+
+	counterMtx.Lock()
+	defer counterMtx.Unlock()
+
+	if resultMap[commons.CMD_STATUS] != "1" {
+		measuredSummary.Errors++
+		return
+	}
+
+	if resultMap[commons.CHECK_STATUS] == CHECK_FAILED {
+		measuredSummary.FailedChecks++
+	}
+
+	elapsed, _ := strconv.ParseFloat(resultMap[commons.ELAPSED_TIME], 64)
+	measuredSummary.Elapsed = append(measuredSummary.Elapsed, elapsed)
+
+	if ttfb, err := strconv.ParseFloat(resultMap[commons.TTFB_TIME], 64); err == nil {
+		measuredSummary.TTFB = append(measuredSummary.TTFB, ttfb)
+	}
+
+	resultParts := strings.Split(resultMap[commons.CMD_RESULT], ", ")
+	if len(resultParts) != 4 {
+		return
+	}
+
+	waitTime, _ := strconv.ParseFloat(resultParts[1], 64)
+	initTime, _ := strconv.ParseFloat(resultParts[2], 64)
+	runTime, _ := strconv.ParseFloat(resultParts[3], 64)
+	measuredSummary.Wait = append(measuredSummary.Wait, waitTime)
+	measuredSummary.Init = append(measuredSummary.Init, initTime)
+	measuredSummary.Run = append(measuredSummary.Run, runTime)
+	if initTime > 0 {
+		measuredSummary.ColdStarts++
+	}
+}
+
+/* the latencies of the summaries, in whole ms */
+func formatMs(value float64) string {
+	return strconv.FormatFloat(value, 'f', 0, 64)
+}
+
+func printSummary() {
+	counterMtx.Lock()
+	defer counterMtx.Unlock()
+
+	elapsedArr := measuredSummary.Elapsed
+	commons.PrintToStdOutOnVerbose("Summary of the measured invocations:")
+	commons.PrintToStdOutOnVerbose("  Invocations: " + strconv.Itoa(len(elapsedArr)+measuredSummary.Errors) + ", Errors: " + strconv.Itoa(measuredSummary.Errors) + ", Cold starts: " + strconv.Itoa(measuredSummary.ColdStarts))
+	commons.PrintToStdOutOnVerbose("  ElapsedTime (ms): mean " + formatMs(commons.Mean(elapsedArr)) + ", p50 " + formatMs(commons.Percentile(elapsedArr, 50)) + ", p90 " + formatMs(commons.Percentile(elapsedArr, 90)) + ", p99 " + formatMs(commons.Percentile(elapsedArr, 99)) + ", max " + formatMs(commons.Percentile(elapsedArr, 100)))
+	if funcVsCheckMap != nil {
+		commons.PrintToStdOutOnVerbose("  Failed result checks: " + strconv.Itoa(measuredSummary.FailedChecks))
+	}
+	if InvokeMode != INVOKE_CLI {
+		commons.PrintToStdOutOnVerbose("  TimeToFirstByte (ms): mean " + formatMs(commons.Mean(measuredSummary.TTFB)) + ", p50 " + formatMs(commons.Percentile(measuredSummary.TTFB, 50)) + ", p99 " + formatMs(commons.Percentile(measuredSummary.TTFB, 99)))
+	}
+	commons.PrintToStdOutOnVerbose("  WaitTime (ms): mean " + formatMs(commons.Mean(measuredSummary.Wait)) + ", InitTime (ms): mean " + formatMs(commons.Mean(measuredSummary.Init)) + ", RunTime (ms): mean " + formatMs(commons.Mean(measuredSummary.Run)))
+}
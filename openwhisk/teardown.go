@@ -0,0 +1,314 @@
+package openwhisk
+
+import (
+	"../commons"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var TeardownPolicy = "none"
+var TeardownRetries = 3
+
+type teardownReport struct {
+	Kind    string
+	Removed int
+	Missing int
+	Failed  []string
+}
+
+/* entities other than the functions, like sequences, paths under the namespace of each user (actions/NAME) */
+type teardownEntities struct {
+	Kind           string
+	UserVsPathsMap map[string][]string
+}
+
+func (obj *teardownReport) String() string {
+	return obj.Kind + ": " + strconv.Itoa(obj.Removed) + " removed, " + strconv.Itoa(obj.Missing) + " did not exist, " + strconv.Itoa(len(obj.Failed)) + " failed"
+}
+
+/*
+Teardown deletes every action, and with the "namespaces" policy every namespace too, listed in a workload file, in
+a scenario (YAML/JSON) or in the workload of a run manifest (the echo action of a payload sweep, the sequences of a DAG
+run & the triggers & rules of a trigger run too). Deletions run across ConcurrencyFactor co-routines and are retried
+TeardownRetries times. OpenWhisk has no API to delete activations, they are left to the retention of the activation
+store.
+*/
+func Teardown(inputFilePath string) {
+	var usersVsFuncsMap map[string]map[int]struct{}
+	var entitiesArr []teardownEntities
+
+	command := ""
+	if strings.HasSuffix(inputFilePath, commons.MANIFEST_SUFFIX) {
+		inputFilePath, command = getManifestWorkload(inputFilePath)
+	}
+
+	switch {
+	case command == "execOWProfile":
+		usersVsFuncsMap = getScenarioFunctions(Scenario{}, parseLoadProfile(inputFilePath))
+	case command == "fireTriggers":
+		_, _, usersVsFuncsMap = parseInputFile(inputFilePath)
+		entitiesArr = getTriggerEntities(usersVsFuncsMap)
+	case command == "sweepPayloads":
+		usersVsFuncsMap = getPayloadFunctions()
+	case command == "execOWDag":
+		dagSpec := parseDagSpec(inputFilePath)
+		usersVsFuncsMap = getDagFunctions(dagSpec)
+		entitiesArr = getSequenceEntities(dagSpec)
+	case commons.ValueInSlice(strings.ToLower(filepath.Ext(inputFilePath)), []string{".yaml", ".yml", ".json"}):
+		scenario := parseScenario(inputFilePath)
+
+		var loadProfile LoadProfile
+		if scenario.Workload.Profile != "" {
+			loadProfile = parseLoadProfile(scenario.Workload.Profile)
+		} else if scenario.Workload.Load != nil {
+			loadProfile = *scenario.Workload.Load
+		}
+
+		usersVsFuncsMap = getScenarioFunctions(scenario, loadProfile)
+	default:
+		_, _, usersVsFuncsMap = parseInputFile(inputFilePath)
+	}
+
+	policy := TeardownPolicy
+	if policy == "none" {
+		policy = "namespaces"
+	}
+
+	doTeardown(usersVsFuncsMap, policy, entitiesArr...)
+}
+
+/* the workload a run manifest was written for & the command it was run with, the teardown goes to its API host */
+func getManifestWorkload(manifestFilePath string) (string, string) {
+	manifest := commons.ReadManifest(manifestFilePath)
+	if manifest.Backend.ApiHost != "" {
+		ApiHost = manifest.Backend.ApiHost
+	}
+
+	switch manifest.Command {
+	case "run", "execOWProfile":
+		return manifest.Args[0], manifest.Command
+	case "sweepPayloads":
+		/* the workload of a sweep is its echo action, the function it ran is the one of its PayloadUser */
+		if payloadUser, ok := manifest.Flags["payloadUser"]; ok {
+			PayloadUser = payloadUser
+		}
+		return manifest.Workload, manifest.Command
+	default:
+		return manifest.Workload, manifest.Command
+	}
+}
+
+/*
+doTeardown removes the functions (policy "actions") or the functions along with their users (policy "namespaces"), the
+other entities go first as they refer to the functions
+*/
+func doTeardown(usersVsFuncsMap map[string]map[int]struct{}, policy string, entitiesArr ...teardownEntities) {
+	if policy == "none" {
+		return
+	}
+
+	if policy != "actions" && policy != "namespaces" {
+		panic(fmt.Errorf("Invalid teardown policy - %s", policy))
+	}
+
+	commons.PrintToStdOutOnVerbose("Tearing down " + policy + " of " + strconv.Itoa(len(usersVsFuncsMap)) + " users:")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+	loadTeardownAuths(usersVsFuncsMap)
+
+	var reportArr []*teardownReport
+	for _, entities := range entitiesArr {
+		reportArr = append(reportArr, removeEntities(entities))
+	}
+
+	reportArr = append(reportArr, removeFunctions(usersVsFuncsMap))
+	if policy == "namespaces" {
+		reportArr = append(reportArr, removeUsers(usersVsFuncsMap))
+	}
+
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	for _, report := range reportArr {
+		fmt.Println(report.String())
+
+		sort.Strings(report.Failed)
+		for _, failure := range report.Failed {
+			fmt.Println("  " + failure)
+		}
+	}
+}
+
+/* unlike loadUserAuths, users that can't be looked up are skipped as their functions are already gone with them */
+func loadTeardownAuths(usersVsFuncsMap map[string]map[int]struct{}) {
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+
+	var exists = struct{}{}
+	uniqueUsersList := make(map[string]struct{})
+	for user := range usersVsFuncsMap {
+		uniqueUsersList[user] = exists
+	}
+	loadCachedAuths(uniqueUsersList)
+
+	for user := range usersVsFuncsMap {
+		if _, ok := userVsAuthMap[user]; ok {
+			continue
+		}
+
+		concChan <- 1
+		wgTime.Add(1)
+
+		go func(user string) {
+			status, output, err := commons.ParseJsonOutput(ExecCmd([]string{"getUserAuth", user}))
+			if err == nil && status == "1" {
+				counterMtx.Lock()
+				userVsAuthMap[user] = output
+				counterMtx.Unlock()
+			} else {
+				commons.PrintToStdOutOnDebug("Skipping the functions of " + user + " - " + output)
+			}
+
+			wgTime.Done()
+			<-concChan
+		}(user)
+	}
+
+	wgTime.Wait()
+}
+
+func removeFunctions(usersVsFuncsMap map[string]map[int]struct{}) *teardownReport {
+	userVsPathsMap := make(map[string][]string)
+	for user, funcList := range usersVsFuncsMap {
+		for funcName := range funcList {
+			userVsPathsMap[user] = append(userVsPathsMap[user], "actions/"+strconv.Itoa(funcName))
+		}
+	}
+
+	return removeEntities(teardownEntities{Kind: "Functions", UserVsPathsMap: userVsPathsMap})
+}
+
+/* the entities of a user without an auth are gone with the user already */
+func removeEntities(entities teardownEntities) *teardownReport {
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+	report := &teardownReport{Kind: entities.Kind}
+
+	startTime := time.Now()
+	for user, pathArr := range entities.UserVsPathsMap {
+		for _, entityPath := range pathArr {
+			concChan <- 1
+			wgTime.Add(1)
+
+			go func(user string, entityPath string) {
+				counterMtx.Lock()
+				userAuth, ok := userVsAuthMap[user]
+				counterMtx.Unlock()
+
+				var isRemoved bool
+				var err error
+				if ok {
+					isRemoved, err = removeEntity(userAuth, entityPath, TeardownRetries)
+				}
+
+				counterMtx.Lock()
+				if err != nil {
+					report.Failed = append(report.Failed, user+"/"+entityPath+" - "+err.Error())
+				} else if isRemoved {
+					report.Removed++
+				} else {
+					report.Missing++
+				}
+				counterMtx.Unlock()
+
+				wgTime.Done()
+				<-concChan
+			}(user, entityPath)
+		}
+	}
+
+	wgTime.Wait()
+	commons.PrintToStdOutOnVerbose(entities.Kind + " torn down. Time taken = " + time.Since(startTime).String())
+	return report
+}
+
+/* delete an entity of the namespace of the auth (actions/NAME, rules/NAME ..), returns false when it didn't exist */
+func removeEntity(userAuth string, entityPath string, retryCount int) (bool, error) {
+	statusCode, respBody, err := doApiRequest("DELETE", "/api/v1/namespaces/_/"+entityPath, userAuth, nil)
+	if err == nil {
+		switch statusCode {
+		case http.StatusOK:
+			return true, nil
+		case http.StatusNotFound:
+			return false, nil
+		default:
+			err = fmt.Errorf("OpenWhisk error - %d %s", statusCode, strings.TrimSpace(string(respBody)))
+		}
+	}
+
+	if retryCount > 0 {
+		time.Sleep(time.Second)
+		return removeEntity(userAuth, entityPath, retryCount-1)
+	}
+
+	return false, err
+}
+
+func removeUsers(usersVsFuncsMap map[string]map[int]struct{}) *teardownReport {
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+	report := &teardownReport{Kind: "Users"}
+
+	startTime := time.Now()
+	for user := range usersVsFuncsMap {
+		concChan <- 1
+		wgTime.Add(1)
+
+		go func(user string) {
+			isRemoved, err := removeUser(user, TeardownRetries)
+			if err == nil {
+				uncacheUserAuth(user)
+			}
+
+			counterMtx.Lock()
+			if err != nil {
+				report.Failed = append(report.Failed, user+" - "+err.Error())
+			} else {
+				delete(userVsAuthMap, user)
+				if isRemoved {
+					report.Removed++
+				} else {
+					report.Missing++
+				}
+			}
+			counterMtx.Unlock()
+
+			wgTime.Done()
+			<-concChan
+		}(user)
+	}
+
+	wgTime.Wait()
+	saveAuthCache()
+	commons.PrintToStdOutOnVerbose("Users torn down. Time taken = " + time.Since(startTime).String())
+	return report
+}
+
+/* delete the user's namespace through wskadmin, returns false when it didn't exist */
+func removeUser(user string, retryCount int) (bool, error) {
+	status, output, err := commons.ParseJsonOutput(ExecCmd([]string{"removeUser", user}))
+	if err == nil && status == "1" {
+		return !strings.HasSuffix(output, "does not exist"), nil
+	}
+
+	if retryCount > 0 {
+		time.Sleep(time.Second)
+		return removeUser(user, retryCount-1)
+	}
+
+	if err == nil {
+		err = fmt.Errorf("wskadmin error - %s", output)
+	}
+
+	return false, err
+}
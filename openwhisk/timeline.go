@@ -0,0 +1,82 @@
+package openwhisk
+
+import (
+	"../analysis"
+	"../commons"
+	"sync/atomic"
+	"time"
+)
+
+var LiveTimeline = false
+var TimelineBinBy = commons.BIN_END
+
+var timelineArr []analysis.Invocation
+var timelinePendingArr []analysis.Invocation
+var inFlightCount int32
+
+/* record the measured invocation for the timeline, warm up rows are left out like in the summary */
+func recordTimeline(resultMap map[string]string) {
+	if !LiveTimeline || resultMap[commons.PHASE] == WARMUP_PHASE {
+		return
+	}
+
+	invocation := analysis.NewInvocation(resultMap)
+
+	counterMtx.Lock()
+	timelineArr = append(timelineArr, invocation)
+	timelinePendingArr = append(timelinePendingArr, invocation)
+	counterMtx.Unlock()
+}
+
+/*
+startTimeline prints a bin of the invocations completed every second while the run goes on, the returned channel stops
+it. The live bins are always by completion, stopTimeline writes the bins of the whole run the way TimelineBinBy says.
+*/
+func startTimeline() chan struct{} {
+	stopChan := make(chan struct{})
+	if !LiveTimeline {
+		return stopChan
+	}
+
+	counterMtx.Lock()
+	timelineArr = nil
+	timelinePendingArr = nil
+	counterMtx.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for second := 0; ; second++ {
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+				counterMtx.Lock()
+				pendingArr := timelinePendingArr
+				timelinePendingArr = nil
+				counterMtx.Unlock()
+
+				bin := analysis.NewTimelineBin(second, pendingArr)
+				bin.InFlight = int(atomic.LoadInt32(&inFlightCount))
+				commons.PrintToStdOutOnVerbose(bin.String())
+			}
+		}
+	}()
+
+	return stopChan
+}
+
+func stopTimeline(stopChan chan struct{}, outputFilePath string) {
+	close(stopChan)
+	if !LiveTimeline {
+		return
+	}
+
+	counterMtx.Lock()
+	invocationArr := timelineArr
+	counterMtx.Unlock()
+
+	commons.PrintToStdOutOnVerbose("Latency over time by " + TimelineBinBy + " second:")
+	analysis.WriteTimeline(analysis.ComputeTimeline(invocationArr, TimelineBinBy), analysis.GetTimelineFilePath(outputFilePath))
+}
@@ -0,0 +1,332 @@
+package openwhisk
+
+import (
+	"../commons"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const TRIGGER_PREFIX = "trigger_"
+const RULE_PREFIX = "rule_"
+
+var FireRate = 0.0
+var DeliveryTimeout = 60
+
+var triggerOrderArr = []string{commons.BATCH, commons.USER_ID, commons.FUNCTION_ID, commons.SEQ, commons.TRIGGER_ACTIVATION_ID, commons.CMD_RESULT, commons.ELAPSED_TIME, commons.FIRE_TIME, commons.FIRE_TO_ACTIVATION, commons.DELIVERY_DELAY, commons.SUBMITTED_AT, commons.ENDED_AT, commons.DELIVERED, commons.CMD_STATUS, commons.PARAMETER}
+
+/* a trigger fire & the activation of the action its rule caused, if any */
+type triggerFire struct {
+	Record              invocationRecord
+	RecordedParam       string
+	SubmittedAt         int64
+	FiredAt             int64
+	IsFired             bool
+	TriggerActivationID string
+}
+
+/* an entry of the logs of a trigger activation, one per rule the fire went through */
+type ruleActivation struct {
+	ActivationID string `json:"activationId"`
+	Action       string `json:"action"`
+	Rule         string `json:"rule"`
+	Success      bool   `json:"success"`
+	StatusCode   int    `json:"statusCode"`
+}
+
+/*
+FireTriggers runs the workload through triggers instead of invoking the functions: every function of a user gets a
+trigger (trigger_N) & a rule binding it to the function (rule_N), an invocation of the workload fires the trigger with
+its param. Fires go out at FireRate per second in a batch (as fast as the co-routines go when 0). Once the fires are
+done, the activation of every fire is resolved from the logs of its trigger activation, waiting up to DeliveryTimeout
+seconds: a fire that was accepted but caused no activation of the function by then is lost.
+
+ElapsedTime is from the fire to the end of the activation & FireTime the fire request itself. FireToActivation is
+from the fire to the start of the activation, it compares the clock of the client with the one of the invoker.
+DeliveryDelay, from the start of the trigger activation to the start of the function activation, is measured on the
+OpenWhisk side only.
+*/
+func FireTriggers(inputFilePath string, outputFilePath string, needCreation bool) {
+	runStartedAt := time.Now()
+
+	batchVsUserFuncMap, uniqueUsersList, usersVsFuncsMap := parseInputFile(inputFilePath)
+	doInitialization(needCreation, uniqueUsersList, usersVsFuncsMap)
+	createTriggers(usersVsFuncsMap)
+
+	commons.PrintToStdOutOnVerbose("Firing triggers across " + strconv.Itoa(commons.ConcurrencyFactor) + " co-routines:")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+	var fireArr []*triggerFire
+	totalExecCount := 0
+	startRun = time.Now()
+	for _, batchOfExecution := range getSortedBatches(batchVsUserFuncMap) {
+		var batchFireArr []*triggerFire
+		for _, userFuncObj := range batchVsUserFuncMap[batchOfExecution] {
+			for i := 1; i <= userFuncObj.NoOfTimesToExecute; i++ {
+				batchFireArr = append(batchFireArr, &triggerFire{Record: createInvocationRecord(userFuncObj, totalExecCount)})
+				totalExecCount++
+			}
+		}
+
+		startBatch := time.Now()
+		fireBatch(batchFireArr, startBatch)
+		completeBatch(batchOfExecution, len(batchFireArr), startBatch)
+
+		fireArr = append(fireArr, batchFireArr...)
+	}
+
+	commons.PrintToStdOutOnVerbose("Fired " + strconv.Itoa(totalExecCount) + " triggers in " + time.Since(startRun).String() + ", resolving their activations")
+
+	if outputFilePath != "" {
+		commons.OutputFileWriter = commons.CreateOutputFile(outputFilePath)
+	}
+
+	commons.PrintHeader(triggerOrderArr, outputFilePath)
+
+	resolveFires(fireArr, time.Now().Add(time.Duration(DeliveryTimeout)*time.Second))
+	commons.OutputFileWriter.Close()
+	commons.WriteManifest(outputFilePath, inputFilePath, runStartedAt)
+
+	doTeardown(usersVsFuncsMap, TeardownPolicy, getTriggerEntities(usersVsFuncsMap)...)
+}
+
+/* create (or update) a trigger & its rule for every function of every user */
+func createTriggers(usersVsFuncsMap map[string]map[int]struct{}) {
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+
+	startTime := time.Now()
+	for user, funcList := range usersVsFuncsMap {
+		for funcName := range funcList {
+			concChan <- 1
+			wgTime.Add(1)
+
+			go func(user string, funcName int) {
+				userAuth := userVsAuthMap[user]
+				triggerName := TRIGGER_PREFIX + strconv.Itoa(funcName)
+
+				err := putEntity(userAuth, "triggers/"+triggerName, map[string]interface{}{})
+				if err == nil {
+					err = putEntity(userAuth, "rules/"+RULE_PREFIX+strconv.Itoa(funcName), map[string]string{
+						"trigger": "/" + user + "/" + triggerName,
+						"action":  "/" + user + "/" + strconv.Itoa(funcName),
+					})
+				}
+				if err != nil {
+					panic(fmt.Errorf("Trigger creation error of %s/%d - %s", user, funcName, err))
+				}
+
+				wgTime.Done()
+				<-concChan
+			}(user, funcName)
+		}
+	}
+
+	wgTime.Wait()
+	commons.PrintToStdOutOnVerbose("Triggers & rules are created. Time taken = " + time.Since(startTime).String())
+}
+
+/* create or update a trigger, rule or any other entity of the namespace of the auth */
+func putEntity(userAuth string, entityPath string, reqBody interface{}) error {
+	statusCode, respBody, err := doApiRequest("PUT", "/api/v1/namespaces/_/"+entityPath+"?overwrite=true", userAuth, reqBody)
+	if err != nil {
+		return err
+	}
+
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("OpenWhisk error - %d %s", statusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+/* fire the triggers of a batch across the co-routines, paced at FireRate */
+func fireBatch(fireArr []*triggerFire, startBatch time.Time) {
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+
+	for idx, fire := range fireArr {
+		if FireRate > 0 {
+			fireAt := startBatch.Add(time.Duration(float64(idx) / FireRate * float64(time.Second)))
+			time.Sleep(time.Until(fireAt))
+		}
+
+		concChan <- 1
+		wgTime.Add(1)
+
+		go func(fire *triggerFire) {
+			param, recordedParam := expandParam(fire.Record.Param, fire.Record.Seq, fire.Record.UserID)
+			fire.RecordedParam = recordedParam
+
+			fire.SubmittedAt = time.Now().UnixNano()
+			fire.TriggerActivationID, fire.IsFired = fireTrigger(fire.Record.UserAuth, TRIGGER_PREFIX+strconv.Itoa(fire.Record.FunctionID), param)
+			fire.FiredAt = time.Now().UnixNano()
+
+			wgTime.Done()
+			<-concChan
+		}(fire)
+	}
+}
+
+/* fire the trigger, returns the id of the trigger activation (empty when no rule is active) & whether it was accepted */
+func fireTrigger(userAuth string, triggerName string, param string) (string, bool) {
+	statusCode, respBody, err := doApiRequestBytes("POST", "/api/v1/namespaces/_/triggers/"+triggerName, userAuth, getParamBody(param))
+	if err != nil {
+		commons.PrintToStdOutOnDebug("Fire error of " + triggerName + " - " + err.Error())
+		return "", false
+	}
+
+	switch statusCode {
+	case http.StatusAccepted, http.StatusOK:
+		var activation activationRecord
+		json.Unmarshal(respBody, &activation)
+		return activation.ActivationID, true
+	case http.StatusNoContent:
+		return "", true
+	}
+
+	commons.PrintToStdOutOnDebug("Fire error of " + triggerName + " - " + strconv.Itoa(statusCode) + " " + strings.TrimSpace(string(respBody)))
+	return "", false
+}
+
+/* write the fires with the activations they caused, in the order they were fired */
+func resolveFires(fireArr []*triggerFire, deadline time.Time) {
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+	resultMapArr := make([]map[string]string, len(fireArr))
+
+	var wgResolve sync.WaitGroup
+	for idx, fire := range fireArr {
+		concChan <- 1
+		wgResolve.Add(1)
+
+		go func(idx int, fire *triggerFire) {
+			resultMapArr[idx] = resolveFire(fire, deadline)
+
+			wgResolve.Done()
+			<-concChan
+		}(idx, fire)
+	}
+	wgResolve.Wait()
+
+	var fireToActivationArr, deliveryDelayArr []float64
+	fireErrors, lostCount := 0, 0
+	for idx, resultMap := range resultMapArr {
+		switch {
+		case !fireArr[idx].IsFired:
+			fireErrors++
+		case resultMap[commons.DELIVERED] == "0":
+			lostCount++
+		default:
+			fireToActivation, _ := strconv.ParseFloat(resultMap[commons.FIRE_TO_ACTIVATION], 64)
+			deliveryDelay, _ := strconv.ParseFloat(resultMap[commons.DELIVERY_DELAY], 64)
+			fireToActivationArr = append(fireToActivationArr, fireToActivation)
+			deliveryDelayArr = append(deliveryDelayArr, deliveryDelay)
+		}
+
+		if commons.WriteToFile {
+			commons.WriteMapToFile(resultMap, triggerOrderArr)
+		} else {
+			commons.WriteMapToOut(resultMap, triggerOrderArr)
+		}
+	}
+
+	firedCount := len(fireArr) - fireErrors
+	lossRate := 0.0
+	if firedCount > 0 {
+		lossRate = float64(lostCount) / float64(firedCount) * 100
+	}
+
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	commons.PrintToStdOutOnVerbose("Summary of the trigger fires:")
+	commons.PrintToStdOutOnVerbose("  Fires: " + strconv.Itoa(len(fireArr)) + ", Fire errors: " + strconv.Itoa(fireErrors) + ", Delivered: " + strconv.Itoa(len(fireToActivationArr)) + ", Lost: " + strconv.Itoa(lostCount) + " (" + strconv.FormatFloat(lossRate, 'f', 2, 64) + "%)")
+	commons.PrintToStdOutOnVerbose("  FireToActivation (ms): mean " + formatMs(commons.Mean(fireToActivationArr)) + ", p50 " + formatMs(commons.Percentile(fireToActivationArr, 50)) + ", p99 " + formatMs(commons.Percentile(fireToActivationArr, 99)) + ", max " + formatMs(commons.Percentile(fireToActivationArr, 100)))
+	commons.PrintToStdOutOnVerbose("  DeliveryDelay (ms): mean " + formatMs(commons.Mean(deliveryDelayArr)) + ", p50 " + formatMs(commons.Percentile(deliveryDelayArr, 50)) + ", p99 " + formatMs(commons.Percentile(deliveryDelayArr, 99)) + ", max " + formatMs(commons.Percentile(deliveryDelayArr, 100)))
+}
+
+/* look up the trigger activation of the fire, then the activation of the function its rule caused */
+func resolveFire(fire *triggerFire, deadline time.Time) map[string]string {
+	resultMap := fire.Record.getCmdMap()
+	delete(resultMap, commons.USER_AUTH)
+	resultMap[commons.PARAMETER] = fire.RecordedParam
+	resultMap[commons.TRIGGER_ACTIVATION_ID] = fire.TriggerActivationID
+	resultMap[commons.SUBMITTED_AT] = strconv.FormatInt(fire.SubmittedAt, 10)
+	resultMap[commons.CMD_RESULT] = "none, 0, 0, 0"
+	resultMap[commons.DELIVERED] = "0"
+	resultMap[commons.CMD_STATUS] = "0"
+
+	if !fire.IsFired {
+		return resultMap
+	}
+	resultMap[commons.FIRE_TIME] = strconv.FormatInt((fire.FiredAt-fire.SubmittedAt)/1000000, 10)
+
+	if fire.TriggerActivationID == "" {
+		commons.PrintToStdOutOnDebug("No active rule for the fire of " + fire.Record.UserID + "/" + TRIGGER_PREFIX + strconv.Itoa(fire.Record.FunctionID))
+		return resultMap
+	}
+
+	triggerActivation, ok := pollActivation(fire.Record.UserAuth, fire.TriggerActivationID, deadline, func(activation activationRecord) bool {
+		return len(activation.Logs) > 0
+	})
+	if !ok {
+		return resultMap
+	}
+
+	var ruleActivationObj ruleActivation
+	if err := json.Unmarshal([]byte(triggerActivation.Logs[0]), &ruleActivationObj); err != nil || ruleActivationObj.ActivationID == "" {
+		commons.PrintToStdOutOnDebug("No activation of " + fire.TriggerActivationID + " - " + triggerActivation.Logs[0])
+		return resultMap
+	}
+
+	activation, ok := pollActivation(fire.Record.UserAuth, ruleActivationObj.ActivationID, deadline, func(activation activationRecord) bool {
+		return activation.End > 0
+	})
+	if !ok {
+		return resultMap
+	}
+
+	submittedAtInMs := fire.SubmittedAt / 1000000
+	resultMap[commons.CMD_RESULT] = activation.getExecResult()
+	resultMap[commons.DELIVERED] = "1"
+	if activation.Response.Success {
+		resultMap[commons.CMD_STATUS] = "1"
+	}
+	resultMap[commons.ENDED_AT] = strconv.FormatInt(activation.End*1000000, 10)
+	resultMap[commons.ELAPSED_TIME] = strconv.FormatInt(activation.End-submittedAtInMs, 10)
+	resultMap[commons.FIRE_TO_ACTIVATION] = strconv.FormatInt(activation.Start-submittedAtInMs, 10)
+	resultMap[commons.DELIVERY_DELAY] = strconv.FormatInt(activation.Start-triggerActivation.Start, 10)
+
+	return resultMap
+}
+
+/* get the activation until isDone or the deadline, the activation records are stored a while after the fact */
+func pollActivation(userAuth string, activationID string, deadline time.Time, isDone func(activationRecord) bool) (activationRecord, bool) {
+	for {
+		var activation activationRecord
+		statusCode, respBody, err := doApiRequest("GET", "/api/v1/namespaces/_/activations/"+activationID, userAuth, nil)
+		if err == nil && statusCode == http.StatusOK && json.Unmarshal(respBody, &activation) == nil && isDone(activation) {
+			return activation, true
+		}
+
+		if time.Now().After(deadline) {
+			commons.PrintToStdOutOnDebug("Activation " + activationID + " not found by the delivery timeout")
+			return activation, false
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+/* the rules & triggers of every function, the rules go first as they refer to the triggers */
+func getTriggerEntities(usersVsFuncsMap map[string]map[int]struct{}) []teardownEntities {
+	userVsRulesMap, userVsTriggersMap := make(map[string][]string), make(map[string][]string)
+	for user, funcList := range usersVsFuncsMap {
+		for funcName := range funcList {
+			userVsRulesMap[user] = append(userVsRulesMap[user], "rules/"+RULE_PREFIX+strconv.Itoa(funcName))
+			userVsTriggersMap[user] = append(userVsTriggersMap[user], "triggers/"+TRIGGER_PREFIX+strconv.Itoa(funcName))
+		}
+	}
+
+	return []teardownEntities{{Kind: "Rules", UserVsPathsMap: userVsRulesMap}, {Kind: "Triggers", UserVsPathsMap: userVsTriggersMap}}
+}
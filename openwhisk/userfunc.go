@@ -1,9 +1,9 @@
 package openwhisk
 
 import (
-	"../commons"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 type UserFuncs struct {
@@ -18,33 +18,69 @@ func (obj UserFuncs) String() string {
 	return "UserFuncs: Time - " + strconv.Itoa(obj.Time) + ", UserID - " + obj.UserID + ", FunctionID - " + strconv.Itoa(obj.FunctionID) + ", NoOfTimeToExecute - " + strconv.Itoa(obj.NoOfTimesToExecute)
 }
 
-func createUserFuncsObj(contents []string) UserFuncs {
+/* parse a line of a workload file: time, user, function, [param,] no. of executions, a JSON param can have commas */
+func parseUserFuncs(contents []string) (UserFuncs, error) {
+	if len(contents) > 5 && isParamTemplate(contents[3]) {
+		param := strings.Join(contents[3:len(contents)-1], ",")
+		contents = []string{contents[0], contents[1], contents[2], param, contents[len(contents)-1]}
+	}
+
 	if len(contents) != 4 && len(contents) != 5 {
-		panic(fmt.Errorf("Invalid Content Length - %d, %+v", len(contents), contents))
+		return UserFuncs{}, fmt.Errorf("Invalid Content Length - %d, %+v", len(contents), contents)
 	}
 
-	userFuncObj := UserFuncs{
-		Time: commons.GetIntFromStr(contents[0]),
-		UserID: func() string {
-			if _, err := strconv.Atoi(contents[1]); err == nil {
-				return "user_" + contents[1]
-			} else {
-				return contents[1]
-			}
-		}(),
-		FunctionID: commons.GetIntFromStr(contents[2]),
-		NoOfTimesToExecute: commons.GetIntFromStr(func() string {
-			if len(contents) == 4 {
-				return contents[3]
-			} else {
-				return contents[4]
-			}
-		}()),
+	parseColumn := func(name string, value string, minValue int) (int, error) {
+		intVal, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("Invalid %s - %q is not a number", name, value)
+		}
+		if intVal < minValue {
+			return 0, fmt.Errorf("Invalid %s - %d is less than %d", name, intVal, minValue)
+		}
+		return intVal, nil
+	}
+
+	var userFuncObj UserFuncs
+	var err error
+	if userFuncObj.Time, err = parseColumn("time", contents[0], 0); err != nil {
+		return UserFuncs{}, err
+	}
+
+	if strings.TrimSpace(contents[1]) == "" {
+		return UserFuncs{}, fmt.Errorf("Invalid user - empty")
+	}
+	userFuncObj.UserID = getUserName(strings.TrimSpace(contents[1]))
+
+	if userFuncObj.FunctionID, err = parseColumn("function", contents[2], 0); err != nil {
+		return UserFuncs{}, err
+	}
+
+	if userFuncObj.NoOfTimesToExecute, err = parseColumn("no. of executions", contents[len(contents)-1], 0); err != nil {
+		return UserFuncs{}, err
 	}
 
 	if len(contents) == 5 {
 		userFuncObj.Param = contents[3]
+		if isParamTemplate(userFuncObj.Param) {
+			if _, err := parseParamTemplate(userFuncObj.Param); err != nil {
+				return UserFuncs{}, err
+			}
+			if InvokeMode == INVOKE_CLI || IsAsync {
+				if err := checkCliParam(userFuncObj.Param); err != nil {
+					return UserFuncs{}, err
+				}
+			}
+		}
+	}
+
+	return userFuncObj, nil
+}
+
+/* numeric user ids map to the user_N namespaces */
+func getUserName(userID string) string {
+	if _, err := strconv.Atoi(userID); err == nil {
+		return "user_" + userID
 	}
 
-	return userFuncObj
+	return userID
 }
@@ -0,0 +1,130 @@
+package openwhisk
+
+import (
+	"../commons"
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxListedErrors = 100
+
+/*
+ValidateWorkload checks every line of a workload file, reporting the bad ones as file:line, and summarizes the valid
+ones: users, functions, invocations per batch, share of every user, parameters & the estimated run time at RateLimit
+invocations/s (with BatchDelay between the batches). Returns false when there's a bad line.
+*/
+func ValidateWorkload(inputFilePath string) bool {
+	fread := openWorkload(inputFilePath)
+	defer fread.Close()
+
+	scanner := bufio.NewScanner(fread)
+
+	var exists = struct{}{}
+	batchVsCountMap := make(map[int]int)
+	userVsCountMap := make(map[string]int)
+	paramVsCountMap := make(map[string]int)
+	uniquePairs := make(map[string]struct{})
+	uniqueFuncs := make(map[int]struct{})
+
+	lineNo, errorCount, totalCount := 0, 0, 0
+	for scanner.Scan() {
+		lineNo++
+		userFuncObj, err := parseUserFuncs(strings.Split(scanner.Text(), ","))
+		if err != nil {
+			errorCount++
+			if errorCount <= maxListedErrors {
+				fmt.Println(inputFilePath + ":" + strconv.Itoa(lineNo) + ": " + err.Error())
+			}
+			continue
+		}
+
+		batchVsCountMap[userFuncObj.Time] += userFuncObj.NoOfTimesToExecute
+		userVsCountMap[userFuncObj.UserID] += userFuncObj.NoOfTimesToExecute
+		paramVsCountMap[userFuncObj.Param] += userFuncObj.NoOfTimesToExecute
+		uniquePairs[userFuncObj.UserID+"/"+strconv.Itoa(userFuncObj.FunctionID)] = exists
+		uniqueFuncs[userFuncObj.FunctionID] = exists
+		totalCount += userFuncObj.NoOfTimesToExecute
+	}
+
+	if err := scanner.Err(); err != nil {
+		panic(fmt.Errorf("File error - %s", err))
+	}
+
+	if errorCount > maxListedErrors {
+		fmt.Println("... " + strconv.Itoa(errorCount-maxListedErrors) + " more bad lines")
+	}
+
+	fmt.Println(inputFilePath + ": " + strconv.Itoa(lineNo) + " lines, " + strconv.Itoa(errorCount) + " bad")
+	fmt.Println("Users: " + strconv.Itoa(len(userVsCountMap)) + ", Functions: " + strconv.Itoa(len(uniqueFuncs)) + " ids, " + strconv.Itoa(len(uniquePairs)) + " (user, function) pairs, Invocations: " + strconv.Itoa(totalCount))
+
+	if totalCount > 0 {
+		printBatchSummary(batchVsCountMap)
+		printShares("Share per user", userVsCountMap, totalCount)
+		printShares("Parameters", paramVsCountMap, totalCount)
+		printEstimatedRunTime(totalCount, len(batchVsCountMap))
+	}
+
+	return errorCount == 0
+}
+
+func printBatchSummary(batchVsCountMap map[int]int) {
+	batchArr := make([]int, 0, len(batchVsCountMap))
+	var countArr []float64
+	for batch, count := range batchVsCountMap {
+		batchArr = append(batchArr, batch)
+		countArr = append(countArr, float64(count))
+	}
+	sort.Ints(batchArr)
+
+	fmt.Println("Batches: " + strconv.Itoa(len(batchArr)) + ", invocations per batch: min " + strconv.FormatFloat(commons.Percentile(countArr, 0), 'f', 0, 64) + ", mean " + strconv.FormatFloat(commons.Mean(countArr), 'f', 1, 64) + ", max " + strconv.FormatFloat(commons.Percentile(countArr, 100), 'f', 0, 64))
+	if len(batchArr) <= 20 {
+		for _, batch := range batchArr {
+			fmt.Println("  Batch " + strconv.Itoa(batch) + ": " + strconv.Itoa(batchVsCountMap[batch]))
+		}
+	}
+}
+
+/* the top 10 keys by their share of the invocations, along with the smallest share */
+func printShares(title string, keyVsCountMap map[string]int, totalCount int) {
+	keyArr := make([]string, 0, len(keyVsCountMap))
+	for key := range keyVsCountMap {
+		keyArr = append(keyArr, key)
+	}
+	sort.Slice(keyArr, func(i, j int) bool {
+		if keyVsCountMap[keyArr[i]] != keyVsCountMap[keyArr[j]] {
+			return keyVsCountMap[keyArr[i]] > keyVsCountMap[keyArr[j]]
+		}
+		return keyArr[i] < keyArr[j]
+	})
+
+	getShare := func(key string) string {
+		name := key
+		if name == "" {
+			name = "(none)"
+		}
+		return name + ": " + strconv.Itoa(keyVsCountMap[key]) + " (" + strconv.FormatFloat(float64(keyVsCountMap[key])*100/float64(totalCount), 'f', 1, 64) + "%)"
+	}
+
+	fmt.Println(title + ": " + strconv.Itoa(len(keyArr)) + " distinct")
+	for idx, key := range keyArr {
+		if idx == 10 {
+			fmt.Println("  ...")
+			fmt.Println("  " + getShare(keyArr[len(keyArr)-1]))
+			break
+		}
+		fmt.Println("  " + getShare(key))
+	}
+}
+
+func printEstimatedRunTime(totalCount int, batchCount int) {
+	if commons.RateLimit <= 0 {
+		fmt.Println("Estimated run time: set -rateLimit to estimate it")
+		return
+	}
+
+	runTimeInSec := float64(totalCount)/commons.RateLimit + float64((batchCount-1)*commons.BatchDelay)/1000
+	fmt.Println("Estimated run time at " + strconv.FormatFloat(commons.RateLimit, 'f', -1, 64) + "/s: " + strconv.FormatFloat(runTimeInSec, 'f', 0, 64) + " s")
+}
@@ -0,0 +1,119 @@
+package openwhisk
+
+import (
+	"../commons"
+	"strconv"
+	"time"
+)
+
+const WARMUP_PHASE = "warmup"
+const MEASURE_PHASE = "measure"
+
+var WarmupCount = 0
+var WarmupDuration = 0
+
+func isWarmupEnabled() bool {
+	return WarmupCount > 0 || WarmupDuration > 0
+}
+
+/*
+runWarmup invokes every (user, function) pair WarmupCount times one after the other, or all the pairs round robin for
+WarmupDuration seconds, before the measured workload. The rows are written with the "warmup" phase and are left out
+of the summary & the execution rate of the run.
+*/
+func runWarmup(pairArr []UserFuncs) {
+	if !isWarmupEnabled() || len(pairArr) == 0 {
+		return
+	}
+
+	commons.PrintToStdOutOnVerbose("Warming up " + strconv.Itoa(len(pairArr)) + " functions:")
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+
+	var concChan = make(chan int, commons.ConcurrencyFactor)
+	warmupExecCount := 0
+	startRun = time.Now()
+
+	if WarmupCount > 0 {
+		for _, userFuncObj := range pairArr {
+			concChan <- 1
+			wgTime.Add(1)
+
+			go func(userFuncObj UserFuncs, seq int) {
+				for i := 0; i < WarmupCount; i++ {
+					invokeAndProcess(createWarmupCmdMap(userFuncObj, seq+i))
+				}
+
+				wgTime.Done()
+				<-concChan
+			}(userFuncObj, warmupExecCount)
+
+			warmupExecCount += WarmupCount
+		}
+	} else {
+		warmupEnd := startRun.Add(time.Duration(WarmupDuration) * time.Second)
+		for time.Now().Before(warmupEnd) {
+			concChan <- 1
+			wgTime.Add(1)
+
+			go func(cmdMap map[string]string) {
+				invokeAndProcess(cmdMap)
+
+				wgTime.Done()
+				<-concChan
+			}(createWarmupCmdMap(pairArr[warmupExecCount%len(pairArr)], warmupExecCount))
+
+			warmupExecCount++
+		}
+	}
+
+	wgTime.Wait()
+
+	counterMtx.Lock()
+	execCount = 0
+	currExecRate = 0
+	counterMtx.Unlock()
+
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+	commons.PrintToStdOutOnVerbose("Warm up completed " + strconv.Itoa(warmupExecCount) + " executions in " + time.Since(startRun).String())
+	commons.PrintToStdOutOnVerbose("------------------------------------------------------------------------")
+}
+
+func createWarmupCmdMap(userFuncObj UserFuncs, seq int) map[string]string {
+	cmdMap := make(map[string]string)
+	cmdMap[commons.BATCH] = "-1"
+	cmdMap[commons.PHASE] = WARMUP_PHASE
+	cmdMap[commons.USER_ID] = userFuncObj.UserID
+	cmdMap[commons.USER_AUTH] = userVsAuthMap[userFuncObj.UserID]
+	cmdMap[commons.FUNCTION_ID] = strconv.Itoa(userFuncObj.FunctionID)
+	cmdMap[commons.PARAMETER] = userFuncObj.Param
+	cmdMap[commons.SEQ] = strconv.Itoa(seq)
+	return cmdMap
+}
+
+/* the phase column is added once, whichever of the warm up or the load profile needs it first */
+func addPhaseColumn() {
+	if !commons.ValueInSlice(commons.PHASE, orderArr) {
+		orderArr = append(orderArr, commons.PHASE)
+	}
+}
+
+/* unique (user, function) pairs drawn by the phases of a load profile */
+func getMixPairs(phaseMixArr []invocationMix) []UserFuncs {
+	var exists = struct{}{}
+	seenPairs := make(map[string]struct{})
+	var pairArr []UserFuncs
+
+	for _, phaseMix := range phaseMixArr {
+		for _, mixEntry := range phaseMix.Entries {
+			pairKey := mixEntry.User + "/" + strconv.Itoa(mixEntry.Function)
+			if _, ok := seenPairs[pairKey]; ok {
+				continue
+			}
+
+			seenPairs[pairKey] = exists
+			pairArr = append(pairArr, UserFuncs{UserID: mixEntry.User, FunctionID: mixEntry.Function, Param: mixEntry.Param})
+		}
+	}
+
+	return pairArr
+}
@@ -0,0 +1,214 @@
+package openwhisk
+
+import (
+	"../commons"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const INVOKE_CLI = "cli"
+const INVOKE_NATIVE = "native"
+const INVOKE_WEB = "web"
+
+/*
+InvokeMode is how the functions are invoked: through ow-bench.sh (cli), the authenticated blocking invoke of the REST
+API (native) or as web actions over plain HTTP (web). The native & web modes record the client side timing of every
+invocation, so a workload run in both modes compares the two paths.
+*/
+var InvokeMode = INVOKE_CLI
+var WebMethod = "POST"
+
+/* auth -> namespace, the web action URLs are per namespace */
+var authVsNamespaceMap = make(map[string]string)
+var namespaceMtx sync.Mutex
+
+/* check the invoke mode once & add its timing columns to the results */
+func initInvokeMode() {
+	if InvokeMode != INVOKE_CLI && InvokeMode != INVOKE_NATIVE && InvokeMode != INVOKE_WEB {
+		panic(fmt.Errorf("Invalid invoke mode - %s", InvokeMode))
+	}
+
+	if InvokeMode == INVOKE_CLI {
+		return
+	}
+
+	if IsAsync {
+		panic(fmt.Errorf("The %s invoke mode only invokes functions blocking, it can't be asynchronous", InvokeMode))
+	}
+
+	if WebMethod != "GET" && WebMethod != "POST" {
+		panic(fmt.Errorf("Invalid web action method - %s", WebMethod))
+	}
+
+	for _, column := range []string{commons.DNS_TIME, commons.CONNECT_TIME, commons.TLS_TIME, commons.TTFB_TIME, commons.TOTAL_TIME} {
+		if !commons.ValueInSlice(column, orderArr) {
+			orderArr = append(orderArr, column)
+		}
+	}
+}
+
+/*
+invokeInMode invokes the function in the InvokeMode, asynchronous invocations always go through ow-bench.sh. Returns
+the status & result like invokeFunctionWithAuth, along with the result of the action & the client side timing of the
+native & web invocations.
+*/
+func invokeInMode(userAuth string, functionID string, param string, isAsync bool) (string, string, json.RawMessage, httpTiming) {
+	switch {
+	case isAsync || InvokeMode == INVOKE_CLI:
+		status, execResult := invokeFunctionWithAuth(userAuth, functionID, param, isAsync)
+		return status, execResult, nil, httpTiming{}
+	case InvokeMode == INVOKE_WEB:
+		return invokeWebAction(userAuth, functionID, param)
+	}
+
+	status, execResult, _, _, result, timing := invokeActionTraced(userAuth, functionID, param)
+	return status, execResult, result, timing
+}
+
+/* the timing columns of the row (ms), left out in the cli mode */
+func (obj httpTiming) addTo(resultMap map[string]string) {
+	if InvokeMode == INVOKE_CLI {
+		return
+	}
+
+	toMs := func(duration time.Duration) string {
+		return strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', 2, 64)
+	}
+
+	resultMap[commons.DNS_TIME] = toMs(obj.DNS)
+	resultMap[commons.CONNECT_TIME] = toMs(obj.Connect)
+	resultMap[commons.TLS_TIME] = toMs(obj.TLS)
+	resultMap[commons.TTFB_TIME] = toMs(obj.TTFB)
+	resultMap[commons.TOTAL_TIME] = toMs(obj.Total)
+}
+
+/* web actions are deployed with the annotations of `wsk action create --web true` */
+func getWebAnnotations() []keyValue {
+	return []keyValue{{Key: "web-export", Value: true}, {Key: "raw-http", Value: false}, {Key: "final", Value: true}}
+}
+
+/*
+invokeWebAction calls the function as a web action, GET with the params in the query or POST with the params as a
+JSON body, without any auth. The .json extension returns the result of the action as is. A web action doesn't return
+its activation, so the result has the activation id (from the response headers) with 0 wait, init & run times, which
+resolveWebResult fills in once the call is timed.
+*/
+func invokeWebAction(userAuth string, functionID string, param string) (string, string, json.RawMessage, httpTiming) {
+	namespace, err := getNamespace(userAuth)
+	if err != nil {
+		commons.PrintToStdOutOnDebug("Invocation error of " + functionID + " - " + err.Error())
+		return "0", "none, 0, 0, 0", nil, httpTiming{}
+	}
+
+	webUrl := strings.TrimSuffix(ApiHost, "/") + "/api/v1/web/" + url.PathEscape(namespace) + "/default/" + functionID + ".json"
+
+	var req *http.Request
+	if WebMethod == "GET" {
+		req, err = http.NewRequest("GET", webUrl+getWebQuery(param), nil)
+	} else {
+		req, err = http.NewRequest("POST", webUrl, bytes.NewReader(getParamBody(param)))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	}
+	if err != nil {
+		commons.PrintToStdOutOnDebug("Invocation error of " + functionID + " - " + err.Error())
+		return "0", "none, 0, 0, 0", nil, httpTiming{}
+	}
+
+	atomic.AddInt32(&inFlightCount, 1)
+	resp, respBody, timing, err := doTracedRequest(req)
+	atomic.AddInt32(&inFlightCount, -1)
+	if err != nil {
+		commons.PrintToStdOutOnDebug("Invocation error of " + functionID + " - " + err.Error())
+		return "0", "none, 0, 0, 0", nil, timing
+	}
+
+	activationID := resp.Header.Get("X-Openwhisk-Activation-Id")
+	if activationID == "" {
+		activationID = "none"
+	}
+
+	status := "1"
+	if resp.StatusCode != http.StatusOK {
+		commons.PrintToStdOutOnDebug("Invocation error of " + functionID + " - " + strconv.Itoa(resp.StatusCode) + " " + strings.TrimSpace(string(respBody)))
+		status = "0"
+	}
+
+	return status, activationID + ", 0, 0, 0", respBody, timing
+}
+
+/* the wait, init & run times of a web action invocation, from its activation record (looked up after the timing) */
+func resolveWebResult(userAuth string, execResult string) string {
+	if InvokeMode != INVOKE_WEB {
+		return execResult
+	}
+
+	activationID := strings.SplitN(execResult, ", ", 2)[0]
+	if activationID == "none" {
+		return execResult
+	}
+
+	_, activationResult, _ := getActivationNative(userAuth, activationID)
+	return activationResult
+}
+
+/* the params of a GET, in the order of the keys, JSON values other than strings are sent JSON encoded */
+func getWebQuery(param string) string {
+	paramMap := getParamValues(param)
+	if len(paramMap) == 0 {
+		return ""
+	}
+
+	keyArr := make([]string, 0, len(paramMap))
+	for key := range paramMap {
+		keyArr = append(keyArr, key)
+	}
+	sort.Strings(keyArr)
+
+	queryArr := make([]string, 0, len(keyArr))
+	for _, key := range keyArr {
+		value, ok := paramMap[key].(string)
+		if !ok {
+			value = toJsonString(paramMap[key])
+		}
+		queryArr = append(queryArr, url.QueryEscape(key)+"="+url.QueryEscape(value))
+	}
+
+	return "?" + strings.Join(queryArr, "&")
+}
+
+/* the namespace of the auth, looked up once */
+func getNamespace(userAuth string) (string, error) {
+	namespaceMtx.Lock()
+	namespace, ok := authVsNamespaceMap[userAuth]
+	namespaceMtx.Unlock()
+	if ok {
+		return namespace, nil
+	}
+
+	statusCode, respBody, err := doApiRequest("GET", "/api/v1/namespaces", userAuth, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var namespaceArr []string
+	if statusCode != http.StatusOK || json.Unmarshal(respBody, &namespaceArr) != nil || len(namespaceArr) == 0 {
+		return "", fmt.Errorf("No namespace - %d %s", statusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	namespaceMtx.Lock()
+	authVsNamespaceMap[userAuth] = namespaceArr[0]
+	namespaceMtx.Unlock()
+
+	return namespaceArr[0], nil
+}
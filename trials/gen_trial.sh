@@ -7,33 +7,24 @@
 #  	0,3,0,foo bar,1
 #  	0,2,0,foo bar,1
 #	...
+#  The files are generated by the generate command of the benchmark,
+#  the params can be a template, e.g. ./gen_trial.sh spin {20-26}
 ######################################################################
 
 export ICNT=${INVOCATION_CNT:=8192}
 export POW2LIMIT=${POW2_LIMIT:=10}
 export LABEL=${TRIAL_LABEL:=""}
 export PARAM=${PARAMS:=}
-if [ $# -eq 2 ]; then PARAM="${@}"; fi # Only support two params at the moment
+if [ $# -gt 0 ]; then PARAM="${*}"; fi
 
-TMPFILE=${TMP_FILE:=/tmp/gen_trial$RANDOM}
-if [ -f $TMPFILE ]; then echo "Error: $TMPFILE exists"; exit 1; fi
-touch $TMPFILE
-for i in $(seq 0 $POW2LIMIT); do 
-	USRS=$((1<<$i))
-	PERUSR=$(($ICNT/$USRS))
-	FILE=${LABEL}${ICNT}_${USRS}u.csv
-	touch $FILE
-	echo "Generating $FILE for $USRS users with $PERUSR invocations"
-	for i in $(seq 1 $PERUSR); do 
-		for j in $(seq 0 $((USRS-1))); do 
-			if [ -z "$PARAM" ]; then
-				echo "0,${j},0,1" >> $TMPFILE
-			else
-				echo "0,${j},0,${PARAM},1" >> $TMPFILE
-			fi
-		done
-	done	
-	cat $TMPFILE | shuf > $FILE
-	true > $TMPFILE
-done
-rm $TMPFILE
+TRIAL_DIR=$PWD
+BENCH_DIR=$(cd "$(dirname "$0")/.." && pwd)
+
+(cd $BENCH_DIR && go run *.go -sweep $POW2LIMIT -sweepTotal $ICNT -param "$PARAM" generate $TRIAL_DIR) || exit 1
+
+if [ -n "$LABEL" ]; then
+	for i in $(seq 0 $POW2LIMIT); do
+		FILE=${ICNT}_$((1<<$i))u.csv
+		mv $FILE ${LABEL}${FILE}
+	done
+fi